@@ -0,0 +1,245 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultConfigPath is the config file used when none is given
+const DefaultConfigPath = "config.json"
+
+// envPrefix starts every environment variable OpenSeat reads its config from
+const envPrefix = "OPENSEAT"
+
+// ConfigSources lists where settings come from, lowest precedence first:
+// built-in defaults, the config file, OPENSEAT_* environment variables, then flags
+type ConfigSources struct {
+	Path      string                      // config file
+	Optional  bool                        // a missing file is not an error
+	LookupEnv func(string) (string, bool) // defaults to os.LookupEnv
+	Flags     ConfigOverrides             // command-line settings
+}
+
+// ConfigOverrides holds settings given as command-line flags. Zero values are unset.
+type ConfigOverrides struct {
+	CRNs          []string
+	CheckInterval int
+	Term          string
+	Campus        string
+	Email         string
+}
+
+// apply replaces config values with any flags that were set
+func (o ConfigOverrides) apply(cfg *Config) {
+	if len(o.CRNs) > 0 {
+		// CRNs also listed in the file keep their settings
+		watches := make(WatchList, 0, len(o.CRNs))
+		for _, crn := range o.CRNs {
+			watches = append(watches, cfg.CRNs.Get(crn))
+		}
+		cfg.CRNs = watches
+	}
+	if o.CheckInterval != 0 {
+		cfg.CheckInterval = o.CheckInterval
+	}
+	if o.Term != "" {
+		cfg.Term = Term(o.Term)
+	}
+	if o.Campus != "" {
+		cfg.Campus = o.Campus
+	}
+	if o.Email != "" {
+		cfg.Email = o.Email
+	}
+}
+
+// CLIOptions is the parsed command line
+type CLIOptions struct {
+	ConfigPath     string
+	ConfigOptional bool // no config file was named, so a missing config.json is fine
+	Demo           bool
+	Overrides      ConfigOverrides
+}
+
+// crnList collects repeated --crn flags, also accepting comma-separated values
+type crnList []string
+
+func (l *crnList) String() string { return strings.Join(*l, ",") }
+
+func (l *crnList) Set(value string) error {
+	for _, crn := range strings.Split(value, ",") {
+		if crn = strings.TrimSpace(crn); crn != "" {
+			*l = append(*l, crn)
+		}
+	}
+	return nil
+}
+
+// ParseArgs parses the monitor's command-line flags. The config path comes
+// from --config, then OPENSEAT_CONFIG, then config.json.
+func ParseArgs(args []string, lookupEnv func(string) (string, bool), out io.Writer) (CLIOptions, error) {
+	fs := flag.NewFlagSet("openseat", flag.ContinueOnError)
+	fs.SetOutput(out)
+
+	var opts CLIOptions
+	var crns crnList
+	configPath := fs.String("config", "", "config file: .json, .yaml, .yml or .toml (default config.json, or $OPENSEAT_CONFIG)")
+	fs.Var(&crns, "crn", "CRN to monitor; repeat or comma-separate for several (replaces crns from the config)")
+	fs.IntVar(&opts.Overrides.CheckInterval, "interval", 0, "seconds between availability checks")
+	fs.StringVar(&opts.Overrides.Term, "term", "", "term, e.g. \"Spring 2026\", next or 202601")
+	fs.StringVar(&opts.Overrides.Campus, "campus", "", "campus code (0 = Blacksburg)")
+	fs.StringVar(&opts.Overrides.Email, "email", "", "email address for notifications")
+	fs.BoolVar(&opts.Demo, "demo", false, "run the demo animation")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Usage: openseat [flags]")
+		fmt.Fprintln(out, "       openseat init [output] [--force]")
+		fmt.Fprintln(out, "       openseat outbox [list|purge]")
+		fmt.Fprintln(out, "       openseat config convert <input> [output] [--to json|yaml|toml]")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Settings are layered: config file < OPENSEAT_* environment variables < flags.")
+		fmt.Fprintln(out)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return CLIOptions{}, err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return CLIOptions{}, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if opts.Overrides.CheckInterval < 0 {
+		return CLIOptions{}, fmt.Errorf("--interval must be positive")
+	}
+	opts.Overrides.CRNs = crns
+
+	opts.ConfigPath, opts.ConfigOptional = configLocation(*configPath, lookupEnv)
+	return opts, nil
+}
+
+// configLocation picks the config file from a --config value, then
+// OPENSEAT_CONFIG, then config.json. Only the default may be missing.
+func configLocation(flagValue string, lookupEnv func(string) (string, bool)) (path string, optional bool) {
+	switch env, ok := lookupEnv(envPrefix + "_CONFIG"); {
+	case flagValue != "":
+		return flagValue, false
+	case ok && env != "":
+		return env, false
+	default:
+		return DefaultConfigPath, true
+	}
+}
+
+// applyEnv sets config fields from OPENSEAT_* environment variables. Names are
+// built from the JSON keys, e.g. checkInterval is OPENSEAT_CHECK_INTERVAL and
+// ntfy.topic is OPENSEAT_NTFY_TOPIC. Lists are comma-separated.
+func applyEnv(cfg *Config, lookupEnv func(string) (string, bool)) error {
+	_, err := applyEnvStruct(reflect.ValueOf(cfg).Elem(), envPrefix, lookupEnv)
+	return err
+}
+
+// applyEnvStruct fills the fields of a struct value and reports whether any variable was set
+func applyEnvStruct(v reflect.Value, prefix string, lookupEnv func(string) (string, bool)) (bool, error) {
+	set := false
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		name := prefix + "_" + envName(key)
+		fv := v.Field(i)
+
+		switch {
+		case fv.Kind() == reflect.Struct && fv.Type().PkgPath() == v.Type().PkgPath():
+			ok, err := applyEnvStruct(fv, name, lookupEnv)
+			if err != nil {
+				return false, err
+			}
+			set = set || ok
+			continue
+		case fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct:
+			// only allocate optional sections when one of their variables is set
+			target := reflect.New(fv.Type().Elem())
+			if !fv.IsNil() {
+				target.Elem().Set(fv.Elem())
+			}
+			ok, err := applyEnvStruct(target.Elem(), name, lookupEnv)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				fv.Set(target)
+				set = true
+			}
+			continue
+		}
+
+		value, ok := lookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setFromEnv(fv, value); err != nil {
+			return false, fmt.Errorf("%s: %w", name, err)
+		}
+		set = true
+	}
+	return set, nil
+}
+
+// setFromEnv parses a variable into a string, int, bool or string list field
+func setFromEnv(fv reflect.Value, value string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		fv.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		fv.SetBool(b)
+	case reflect.Slice:
+		var list crnList
+		list.Set(value)
+		switch {
+		case fv.Type() == reflect.TypeOf(WatchList{}):
+			// CRNs also listed in the file keep their settings
+			file := fv.Interface().(WatchList)
+			watches := make(WatchList, 0, len(list))
+			for _, crn := range list {
+				watches = append(watches, file.Get(crn))
+			}
+			fv.Set(reflect.ValueOf(watches))
+		case fv.Type().Elem().Kind() == reflect.String:
+			fv.Set(reflect.ValueOf([]string(list)))
+		default:
+			return fmt.Errorf("can't be set from the environment")
+		}
+	default:
+		return fmt.Errorf("can't be set from the environment")
+	}
+	return nil
+}
+
+// envName converts a JSON key like "checkInterval" to "CHECK_INTERVAL"
+func envName(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
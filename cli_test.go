@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// ===================
+// Flag and environment tests
+// ===================
+
+// fakeEnv returns a LookupEnv function backed by a map
+func fakeEnv(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func TestParseArgs_Flags(t *testing.T) {
+	opts, err := ParseArgs([]string{"--crn", "12345", "--crn=67890,11111", "--interval", "45", "--term", "202609", "--email", "me@vt.edu"}, fakeEnv(nil), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(opts.Overrides.CRNs, []string{"12345", "67890", "11111"}) {
+		t.Errorf("CRNs = %v", opts.Overrides.CRNs)
+	}
+	if opts.Overrides.CheckInterval != 45 || opts.Overrides.Term != "202609" || opts.Overrides.Email != "me@vt.edu" {
+		t.Errorf("unexpected overrides: %+v", opts.Overrides)
+	}
+	if opts.ConfigPath != DefaultConfigPath || !opts.ConfigOptional {
+		t.Errorf("expected optional default config, got %q (optional %v)", opts.ConfigPath, opts.ConfigOptional)
+	}
+}
+
+func TestParseArgs_ConfigPathPrecedence(t *testing.T) {
+	env := fakeEnv(map[string]string{"OPENSEAT_CONFIG": "/etc/openseat.json"})
+
+	opts, _ := ParseArgs(nil, env, io.Discard)
+	if opts.ConfigPath != "/etc/openseat.json" || opts.ConfigOptional {
+		t.Errorf("env config path = %q (optional %v)", opts.ConfigPath, opts.ConfigOptional)
+	}
+
+	opts, _ = ParseArgs([]string{"--config", "mine.json"}, env, io.Discard)
+	if opts.ConfigPath != "mine.json" {
+		t.Errorf("flag config path = %q, want mine.json", opts.ConfigPath)
+	}
+}
+
+func TestParseArgs_Errors(t *testing.T) {
+	for _, args := range [][]string{{"--interval", "soon"}, {"--nope"}, {"extra"}} {
+		if _, err := ParseArgs(args, fakeEnv(nil), io.Discard); err == nil {
+			t.Errorf("ParseArgs(%v): expected error", args)
+		}
+	}
+}
+
+func TestApplyEnv_NestedFields(t *testing.T) {
+	cfg := Config{CheckInterval: 30}
+	err := applyEnv(&cfg, fakeEnv(map[string]string{
+		"OPENSEAT_CRNS":                  "12345, 67890",
+		"OPENSEAT_CHECK_INTERVAL":        "90",
+		"OPENSEAT_CONTINUOUS":            "true",
+		"OPENSEAT_NTFY_TOPIC":            "vt-seats",
+		"OPENSEAT_SMS_ACCOUNT_SID":       "AC123",
+		"OPENSEAT_ALERTS_CONFIRM_CHECKS": "2",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.CRNs.CRNs(), []string{"12345", "67890"}) || cfg.CheckInterval != 90 || !cfg.Continuous {
+		t.Errorf("top-level fields not applied: %+v", cfg)
+	}
+	if cfg.Ntfy == nil || cfg.Ntfy.Topic != "vt-seats" {
+		t.Errorf("ntfy not configured from env: %+v", cfg.Ntfy)
+	}
+	if cfg.SMS == nil || cfg.SMS.AccountSID != "AC123" {
+		t.Errorf("sms not configured from env: %+v", cfg.SMS)
+	}
+	if cfg.Alerts.ConfirmChecks != 2 {
+		t.Errorf("alerts.confirmChecks = %d, want 2", cfg.Alerts.ConfirmChecks)
+	}
+	if cfg.Gotify != nil || cfg.Telegram != nil {
+		t.Error("sections without variables should stay unset")
+	}
+
+	if err := applyEnv(&cfg, fakeEnv(map[string]string{"OPENSEAT_CHECK_INTERVAL": "often"})); err == nil {
+		t.Error("expected error for invalid integer")
+	}
+}
+
+func TestLoadConfigFrom_Precedence(t *testing.T) {
+	path := createTempConfig(t, `{"crns": ["11111"], "checkInterval": 60, "term": "202601", "email": "file@vt.edu"}`)
+
+	cfg, err := loadConfigFrom(ConfigSources{
+		Path:      path,
+		LookupEnv: fakeEnv(map[string]string{"OPENSEAT_CHECK_INTERVAL": "45", "OPENSEAT_TERM": "202606"}),
+		Flags:     ConfigOverrides{Term: "202609"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CheckInterval != 45 {
+		t.Errorf("env should override file: interval = %d", cfg.CheckInterval)
+	}
+	if cfg.Term != "202609" {
+		t.Errorf("flag should override env: term = %q", cfg.Term)
+	}
+	if cfg.Email != "file@vt.edu" || !slices.Equal(cfg.CRNs.CRNs(), []string{"11111"}) {
+		t.Errorf("file values should remain: %+v", cfg)
+	}
+}
+
+func TestLoadConfigFrom_OptionalFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := loadConfigFrom(ConfigSources{Path: missing, Optional: true, LookupEnv: fakeEnv(nil), Flags: ConfigOverrides{CRNs: []string{"12345"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CheckInterval != 30 || cfg.Term != "202601" {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+
+	if _, err := loadConfigFrom(ConfigSources{Path: missing, LookupEnv: fakeEnv(nil)}); err == nil {
+		t.Error("expected error for a named config file that doesn't exist")
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFormat is a file format a config can be written in
+type configFormat string
+
+const (
+	formatJSON configFormat = "json"
+	formatYAML configFormat = "yaml"
+	formatTOML configFormat = "toml"
+)
+
+// formatForPath picks the format from a file's extension, defaulting to JSON
+func formatForPath(path string) configFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	default:
+		return formatJSON
+	}
+}
+
+// parseFormat reads a format name as given on the command line
+func parseFormat(name string) (configFormat, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return formatJSON, nil
+	case "yaml", "yml":
+		return formatYAML, nil
+	case "toml":
+		return formatTOML, nil
+	}
+	return "", fmt.Errorf("unknown config format %q (use json, yaml or toml)", name)
+}
+
+// configJSON converts a config file to JSON so every format goes through the
+// same decoding, defaults and validation. JSON is returned unchanged.
+func configJSON(data []byte, format configFormat) ([]byte, error) {
+	if format == formatJSON {
+		return data, nil
+	}
+	raw, err := parseRawConfig(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+// parseRawConfig parses a config file into generic maps, lists and scalars
+func parseRawConfig(data []byte, format configFormat) (any, error) {
+	var raw any
+	switch format {
+	case formatYAML:
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %v", strings.TrimPrefix(err.Error(), "yaml: "))
+		}
+	case formatTOML:
+		var table map[string]any
+		if err := toml.Unmarshal(data, &table); err != nil {
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("invalid TOML at line %d, column %d: %s", parseErr.Position.Line, parseErr.Position.Col, parseErr.Message)
+			}
+			return nil, fmt.Errorf("invalid TOML: %w", err)
+		}
+		raw = table
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			var syntax *json.SyntaxError
+			if errors.As(err, &syntax) {
+				line, col := position(data, syntax.Offset)
+				return nil, fmt.Errorf("invalid JSON at line %d, column %d: %v", line, col, err)
+			}
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+	return normalizeRaw(raw), nil
+}
+
+// normalizeRaw gives YAML maps string keys, turns JSON numbers into ints where
+// possible, turns bare dates back into YYYY-MM-DD strings and drops nulls,
+// which TOML can't represent
+func normalizeRaw(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(value))
+		for k, item := range value {
+			if item != nil {
+				out[k] = normalizeRaw(item)
+			}
+		}
+		return out
+	case map[any]any:
+		out := make(map[string]any, len(value))
+		for k, item := range value {
+			if item != nil {
+				out[fmt.Sprint(k)] = normalizeRaw(item)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, 0, len(value))
+		for _, item := range value {
+			if item != nil {
+				out = append(out, normalizeRaw(item))
+			}
+		}
+		return out
+	case []map[string]any: // TOML arrays of tables
+		out := make([]any, len(value))
+		for i, item := range value {
+			out[i] = normalizeRaw(item)
+		}
+		return out
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n
+		}
+		f, _ := value.Float64()
+		return f
+	case time.Time:
+		// YAML reads an unquoted 2026-01-20 as midnight UTC and TOML as a local
+		// date; either way it means the whole day, as the same JSON string does
+		midnight := value.Hour() == 0 && value.Minute() == 0 && value.Second() == 0 && value.Nanosecond() == 0
+		if zone := value.Location().String(); midnight && (zone == "UTC" || zone == "date-local") {
+			return value.Format(time.DateOnly)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+// encodeRawConfig writes parsed config values in the given format
+func encodeRawConfig(raw any, format configFormat) ([]byte, error) {
+	switch format {
+	case formatYAML:
+		var b bytes.Buffer
+		enc := yaml.NewEncoder(&b)
+		enc.SetIndent(2)
+		if err := enc.Encode(raw); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), enc.Close()
+	case formatTOML:
+		if _, ok := raw.(map[string]any); !ok {
+			return nil, fmt.Errorf("a TOML config must be a table of settings")
+		}
+		var b bytes.Buffer
+		enc := toml.NewEncoder(&b)
+		enc.Indent = ""
+		if err := enc.Encode(raw); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	default:
+		data, err := json.MarshalIndent(raw, "", "  ")
+		return append(data, '\n'), err
+	}
+}
+
+// ConvertConfig translates a config file between JSON, YAML and TOML. The
+// input is checked for unknown keys and wrongly typed values first, so a typo
+// isn't carried over. Comments are not preserved.
+func ConvertConfig(data []byte, from, to configFormat) ([]byte, error) {
+	raw, err := parseRawConfig(data, from)
+	if err != nil {
+		return nil, err
+	}
+	jsonData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	fieldErrs, err := decodeConfig(jsonData, new(Config))
+	if err != nil {
+		return nil, err
+	}
+	if len(fieldErrs) > 0 {
+		return nil, fieldErrs
+	}
+	return encodeRawConfig(raw, to)
+}
+
+// RunConfigCommand handles "openseat config convert", writing the converted
+// config to a file or, without one, to out
+func RunConfigCommand(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(out)
+	to := fs.String("to", "", "output format: json, yaml or toml (default from the output file's extension)")
+	force := fs.Bool("force", false, "overwrite an existing output file")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Usage: openseat config convert <input> [output] [--to json|yaml|toml] [--force]")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "convert" {
+		fs.Usage()
+		if len(args) == 0 {
+			return fmt.Errorf("missing config action")
+		}
+		return fmt.Errorf("unknown config action %q", args[0])
+	}
+	paths, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 || len(paths) > 2 {
+		fs.Usage()
+		return fmt.Errorf("expected an input file and an optional output file")
+	}
+
+	input := paths[0]
+	var output string
+	if len(paths) == 2 {
+		output = paths[1]
+	}
+	var format configFormat
+	switch {
+	case *to != "":
+		if format, err = parseFormat(*to); err != nil {
+			return err
+		}
+	case output != "":
+		format = formatForPath(output)
+	default:
+		fs.Usage()
+		return fmt.Errorf("--to is required when writing to standard output")
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	converted, err := ConvertConfig(data, formatForPath(input), format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", input, err)
+	}
+
+	if output == "" {
+		_, err = out.Write(converted)
+		return err
+	}
+	if _, err := os.Stat(output); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", output)
+	}
+	if err := os.WriteFile(output, converted, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Fprintf(out, "Converted %s to %s (%s). Comments are not carried over.\n", input, output, format)
+	return nil
+}
+
+// parseInterspersed parses flags that may appear before, between or after
+// positional arguments, returning the positional ones in order
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ===================
+// Config format tests
+// ===================
+
+// writeConfigFile writes content to a file with the given name in a temp dir
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const formatTestJSON = `{
+  "crns": ["12345", "67890"],
+  "checkInterval": 60,
+  "ntfy": {"topic": "seats"},
+  "quietHours": {"channels": {"sms": {"start": "22:00", "end": "07:00", "action": "hold"}}}
+}`
+
+const formatTestYAML = `# watched sections
+crns:
+  - "12345"
+  - "67890" # lab
+checkInterval: 60
+ntfy:
+  topic: seats
+quietHours:
+  channels:
+    sms: {start: "22:00", end: "07:00", action: hold}
+`
+
+const formatTestTOML = `# watched sections
+crns = ["12345", "67890"]
+checkInterval = 60
+
+[ntfy]
+topic = "seats"
+
+[quietHours.channels.sms]
+start = "22:00"
+end = "07:00"
+action = "hold"
+`
+
+func TestLoadConfig_FormatsMatch(t *testing.T) {
+	want, err := loadConfigFrom(ConfigSources{Path: writeConfigFile(t, "config.json", formatTestJSON), LookupEnv: fakeEnv(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"config.yaml": formatTestYAML,
+		"config.yml":  formatTestYAML,
+		"config.toml": formatTestTOML,
+	} {
+		got, err := loadConfigFrom(ConfigSources{Path: writeConfigFile(t, name, content), LookupEnv: fakeEnv(nil)})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestLoadConfig_YAMLValidatedLikeJSON(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "crns: [\"1234\"]\ncheckInterval: \"60\"\nntfy:\n  topik: seats\n")
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"ntfy.topik: unknown field",
+		"checkInterval: expected a whole number, got string",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadConfig_NumericCRNs(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", "crns:\n  - 12345\n  - crn: 67890\n    label: Lab\nsms:\n  crns: [12345]\nquietHours:\n  critical: [67890]\n")
+
+	cfg, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.CRNs.CRNs(), []string{"12345", "67890"}) || cfg.CRNs.Get("67890").Label != "Lab" {
+		t.Errorf("unexpected watches: %+v", cfg.CRNs)
+	}
+	if !slices.Equal(cfg.SMS.CRNs, []string{"12345"}) || !slices.Equal(cfg.QuietHours.Critical, []string{"67890"}) {
+		t.Errorf("unexpected channel CRNs: %v, %v", cfg.SMS.CRNs, cfg.QuietHours.Critical)
+	}
+}
+
+func TestLoadConfig_NumericStrings(t *testing.T) {
+	for name, content := range map[string]string{
+		"config.yaml": "crns: [12345]\nterm: 202601\ncampus: 0\ntelegram:\n  token: abc\n  chatId: -1001234\n",
+		"config.toml": "crns = [12345]\nterm = 202601\ncampus = 0\n[telegram]\ntoken = \"abc\"\nchatId = -1001234\n",
+	} {
+		path := writeConfigFile(t, name, content)
+
+		cfg, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if cfg.Term != "202601" || cfg.Campus != "0" || cfg.Telegram.ChatID != "-1001234" {
+			t.Errorf("%s: got term %q, campus %q, chat %q", name, cfg.Term, cfg.Campus, cfg.Telegram.ChatID)
+		}
+	}
+}
+
+func TestLoadConfig_BareDatesMatchJSON(t *testing.T) {
+	want, err := loadConfigFrom(ConfigSources{
+		Path:      writeConfigFile(t, "config.json", `{"crns": [{"crn": "12345", "expires": "2026-01-20"}]}`),
+		LookupEnv: fakeEnv(nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEnd, _ := want.CRNs[0].expiry()
+
+	for name, content := range map[string]string{
+		"config.yaml": "crns:\n  - crn: 12345\n    expires: 2026-01-20\n",
+		"config.toml": "[[crns]]\ncrn = \"12345\"\nexpires = 2026-01-20\n",
+	} {
+		cfg, err := loadConfigFrom(ConfigSources{Path: writeConfigFile(t, name, content), LookupEnv: fakeEnv(nil)})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		end, _ := cfg.CRNs[0].expiry()
+		if cfg.CRNs[0].Expires != want.CRNs[0].Expires || !end.Equal(wantEnd) {
+			t.Errorf("%s: expires %q (%v), want %q (%v)", name, cfg.CRNs[0].Expires, end, want.CRNs[0].Expires, wantEnd)
+		}
+	}
+}
+
+func TestLoadConfig_TOMLSyntaxErrorPosition(t *testing.T) {
+	path := writeConfigFile(t, "config.toml", "crns = [\"12345\"]\ncheckInterval = = 60\n")
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil || !strings.Contains(err.Error(), "invalid TOML at line 2") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestConvertConfig_RoundTrip(t *testing.T) {
+	for _, to := range []configFormat{formatYAML, formatTOML, formatJSON} {
+		converted, err := ConvertConfig([]byte(formatTestJSON), formatJSON, to)
+		if err != nil {
+			t.Fatalf("%s: %v", to, err)
+		}
+		if to == formatTOML && strings.Contains(string(converted), "60.0") {
+			t.Errorf("integers should stay integers in TOML:\n%s", converted)
+		}
+		back, err := ConvertConfig(converted, to, formatJSON)
+		if err != nil {
+			t.Fatalf("%s back to JSON: %v", to, err)
+		}
+		want, _ := ConvertConfig([]byte(formatTestJSON), formatJSON, formatJSON)
+		if !bytes.Equal(back, want) {
+			t.Errorf("%s round trip changed the config:\n%s\nwant:\n%s", to, back, want)
+		}
+	}
+}
+
+func TestConvertConfig_RejectsUnknownFields(t *testing.T) {
+	_, err := ConvertConfig([]byte(`{"crns": ["12345"], "ntfy": {"topik": "x"}}`), formatJSON, formatYAML)
+	if err == nil || !strings.Contains(err.Error(), "ntfy.topik: unknown field") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRunConfigCommand_Convert(t *testing.T) {
+	input := writeConfigFile(t, "config.json", formatTestJSON)
+	output := filepath.Join(filepath.Dir(input), "config.toml")
+
+	var out bytes.Buffer
+	if err := RunConfigCommand([]string{"convert", input, output}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfigFrom(ConfigSources{Path: output, LookupEnv: fakeEnv(nil)}); err != nil {
+		t.Errorf("converted config doesn't load: %v", err)
+	}
+	if err := RunConfigCommand([]string{"convert", input, output}, &out); err == nil {
+		t.Error("expected an error overwriting without --force")
+	}
+	if err := RunConfigCommand([]string{"convert", input, output, "--force"}, &out); err != nil {
+		t.Errorf("--force: %v", err)
+	}
+
+	out.Reset()
+	if err := RunConfigCommand([]string{"convert", "--to", "yaml", input}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "topic: seats") {
+		t.Errorf("unexpected YAML output:\n%s", out.String())
+	}
+
+	if err := RunConfigCommand([]string{"convert", input}, &out); err == nil {
+		t.Error("expected an error without --to or an output file")
+	}
+	if err := RunConfigCommand([]string{"merge", input}, &out); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// DesktopConfig configures local attention signals for when the monitor runs
+// in a background terminal or tmux pane
+type DesktopConfig struct {
+	Notify bool   `json:"notify"` // Raise a freedesktop notification over D-Bus (Linux)
+	Bell   bool   `json:"bell"`   // Ring the terminal bell
+	OSC    string `json:"osc"`    // Terminal notification escape: "9", "777" or "" to disable
+	Title  bool   `json:"title"`  // Show live found/total counts in the terminal window title
+}
+
+// urgencyCritical is the freedesktop "urgency" hint value for critical notifications
+const urgencyCritical byte = 2
+
+// DesktopNotifier alerts the local user through the desktop and terminal
+type DesktopNotifier struct {
+	Config DesktopConfig
+
+	out  io.Writer                                      // terminal output (defaults to stdout)
+	dbus func(summary, body string, urgency byte) error // D-Bus sender (defaults to the session bus)
+}
+
+func (n *DesktopNotifier) Name() string { return "desktop" }
+
+// PrefersUrgent signals each opening locally as soon as it's seen
+func (n *DesktopNotifier) PrefersUrgent() bool { return true }
+
+// Accepts limits local alerts to seat openings
+func (n *DesktopNotifier) Accepts(ev Event) bool {
+	return ev.Kind == EventSeatOpen
+}
+
+func (n *DesktopNotifier) Notify(ev Event) error {
+	out := n.writer()
+
+	if n.Config.Bell {
+		fmt.Fprint(out, "\a")
+	}
+	switch n.Config.OSC {
+	case "9":
+		fmt.Fprint(out, tmuxPassthrough(fmt.Sprintf("\033]9;%s\007", sanitizeOSC(ev.Body()))))
+	case "777":
+		fmt.Fprint(out, tmuxPassthrough(fmt.Sprintf("\033]777;notify;%s;%s\007", sanitizeOSC(ev.Title()), sanitizeOSC(ev.Body()))))
+	}
+
+	if !n.Config.Notify {
+		return nil
+	}
+	send := n.dbus
+	if send == nil {
+		send = sendDBusNotification
+	}
+	if err := send(ev.Title(), ev.Body(), urgencyCritical); err != nil {
+		return fmt.Errorf("dbus: %w", err)
+	}
+	return nil
+}
+
+// UpdateStatus shows the live found/total counts in the terminal window title
+func (n *DesktopNotifier) UpdateStatus(found, total int) {
+	if !n.Config.Title {
+		return
+	}
+	fmt.Fprintf(n.writer(), "\033]0;OpenSeat %d/%d found\007", found, total)
+}
+
+func (n *DesktopNotifier) writer() io.Writer {
+	if n.out != nil {
+		return n.out
+	}
+	return os.Stdout
+}
+
+// sendDBusNotification raises a notification through org.freedesktop.Notifications
+func sendDBusNotification(summary, body string, urgency byte) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(urgency)}
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.Call("org.freedesktop.Notifications.Notify", 0,
+		"OpenSeat", // app_name
+		uint32(0),  // replaces_id
+		"",         // app_icon
+		summary,    // summary
+		body,       // body
+		[]string{}, // actions
+		hints,      // hints
+		int32(-1),  // expire_timeout (server default)
+	)
+	return call.Err
+}
+
+// tmuxPassthrough wraps an escape sequence so tmux forwards it to the outer terminal
+func tmuxPassthrough(seq string) string {
+	if os.Getenv("TMUX") == "" {
+		return seq
+	}
+	return "\033Ptmux;" + strings.ReplaceAll(seq, "\033", "\033\033") + "\033\\"
+}
+
+// sanitizeOSC strips characters that would terminate or split an OSC sequence
+func sanitizeOSC(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\007' || r == '\033' || r == ';' || r == '\n' {
+			return ' '
+		}
+		return r
+	}, s)
+}
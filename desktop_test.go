@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// ===================
+// DesktopNotifier tests
+// ===================
+
+func TestDesktopNotifier_TerminalSignals(t *testing.T) {
+	t.Setenv("TMUX", "")
+	var out bytes.Buffer
+	n := &DesktopNotifier{Config: DesktopConfig{Bell: true, OSC: "777"}, out: &out}
+
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := out.String()
+	if !strings.HasPrefix(got, "\a") {
+		t.Errorf("expected bell first, got %q", got)
+	}
+	want := "\033]777;notify;Seat open: Intro to Testing;OPEN SEAT: Intro to Testing (CRN: 12345)\007"
+	if !strings.Contains(got, want) {
+		t.Errorf("output = %q, want it to contain %q", got, want)
+	}
+}
+
+func TestDesktopNotifier_OSC9InsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+	var out bytes.Buffer
+	n := &DesktopNotifier{Config: DesktopConfig{OSC: "9"}, out: &out}
+
+	n.Notify(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro"})
+
+	want := "\033Ptmux;\033\033]9;OPEN SEAT: Intro (CRN: 12345)\007\033\\"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestDesktopNotifier_DBus(t *testing.T) {
+	var gotSummary string
+	var gotUrgency byte
+	n := &DesktopNotifier{
+		Config: DesktopConfig{Notify: true},
+		out:    &bytes.Buffer{},
+		dbus: func(summary, body string, urgency byte) error {
+			gotSummary, gotUrgency = summary, urgency
+			return nil
+		},
+	}
+
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSummary != "Seat open: Intro to Testing" || gotUrgency != urgencyCritical {
+		t.Errorf("got summary %q urgency %d", gotSummary, gotUrgency)
+	}
+}
+
+func TestDesktopNotifier_UpdateStatusTitle(t *testing.T) {
+	var out bytes.Buffer
+	n := &DesktopNotifier{Config: DesktopConfig{Title: true}, out: &out}
+
+	n.UpdateStatus(1, 3)
+
+	if got, want := out.String(), "\033]0;OpenSeat 1/3 found\007"; got != want {
+		t.Errorf("title = %q, want %q", got, want)
+	}
+}
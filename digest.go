@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DigestConfig schedules periodic status reports for long-running watches
+type DigestConfig struct {
+	Schedule string `json:"schedule"` // "daily" or "weekly" (required)
+	Time     string `json:"time"`     // Local time of day to send, HH:MM (default 08:00)
+	Weekday  string `json:"weekday"`  // Day for weekly digests, e.g. "monday" (default monday)
+}
+
+// validate reports parts of the schedule that can't be parsed under path, e.g. "digest"
+func (d DigestConfig) validate(path string, errs *ConfigErrors) {
+	if d.Schedule != "daily" && d.Schedule != "weekly" {
+		errs.add(path+".schedule", "must be \"daily\" or \"weekly\", got %q", d.Schedule)
+	}
+	if _, _, err := d.clock(); err != nil {
+		errs.add(path+".time", "must be HH:MM, got %q", d.Time)
+	}
+	if _, err := d.weekday(); err != nil {
+		errs.add(path+".weekday", "must be a day name, got %q", d.Weekday)
+	}
+}
+
+// clock returns the configured hour and minute
+func (d DigestConfig) clock() (hour, minute int, err error) {
+	value := d.Time
+	if value == "" {
+		value = "08:00"
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("digest time must be HH:MM, got %q", d.Time)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// weekday returns the configured day for weekly digests
+func (d DigestConfig) weekday() (time.Weekday, error) {
+	if d.Weekday == "" {
+		return time.Monday, nil
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(d.Weekday, day.String()) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("digest weekday must be a day name, got %q", d.Weekday)
+}
+
+// next returns the first scheduled digest time strictly after the given time
+func (d DigestConfig) next(after time.Time) time.Time {
+	hour, minute, _ := d.clock()
+	day, _ := d.weekday()
+
+	t := time.Date(after.Year(), after.Month(), after.Day(), hour, minute, 0, 0, after.Location())
+	for !t.After(after) || (d.Schedule == "weekly" && t.Weekday() != day) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// buildDigest summarizes the monitor's history as a digest event
+func buildDigest(courses []CourseStatus, started, now time.Time) Event {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Monitoring since %s (%s)\n", started.Format("Jan 2 15:04"), formatDuration(now.Sub(started)))
+
+	closedCount, checks, errors := 0, 0, 0
+	for _, c := range courses {
+		if !c.Open && !c.Found {
+			closedCount++
+		}
+		checks += c.Stats.Checks
+		errors += c.Stats.Errors
+	}
+	fmt.Fprintf(&b, "%d of %d CRNs still closed\n", closedCount, len(courses))
+
+	for _, c := range courses {
+		state := "closed"
+		switch {
+		case c.Open:
+			state = "OPEN"
+		case c.Found:
+			state = "done"
+		}
+
+		openTime := c.Stats.OpenTime
+		if !c.Stats.OpenSince.IsZero() {
+			openTime += now.Sub(c.Stats.OpenSince)
+		}
+		seats := "unknown"
+		if c.Stats.Seats >= 0 {
+			seats = fmt.Sprint(c.Stats.Seats)
+		}
+
+		fmt.Fprintf(&b, "\n%s %s — %s\n", c.CRN, c.Name, state)
+		fmt.Fprintf(&b, "  checks %d, errors %d (%s), opened %dx for %s, seats %s\n",
+			c.Stats.Checks, c.Stats.Errors, errorRate(c.Stats.Errors, c.Stats.Checks),
+			c.Stats.Openings, formatDuration(openTime), seats)
+	}
+
+	fmt.Fprintf(&b, "\nTotal: %d checks, %d errors (%s)", checks, errors, errorRate(errors, checks))
+
+	return Event{
+		Kind:    EventDigest,
+		Time:    now,
+		Message: b.String(),
+	}
+}
+
+// errorRate formats errors as a percentage of checks
+func errorRate(errors, checks int) string {
+	if checks == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(errors)*100/float64(checks))
+}
+
+// formatDuration renders a duration compactly, e.g. "3d 4h" or "12m"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
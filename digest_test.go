@@ -0,0 +1,92 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// DigestConfig tests
+// ===================
+
+func TestDigestConfig_NextDaily(t *testing.T) {
+	d := DigestConfig{Schedule: "daily", Time: "08:30"}
+	loc := time.UTC
+
+	before := time.Date(2026, 1, 7, 7, 0, 0, 0, loc)
+	if got, want := d.next(before), time.Date(2026, 1, 7, 8, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("next(%v) = %v, want %v", before, got, want)
+	}
+
+	after := time.Date(2026, 1, 7, 8, 30, 0, 0, loc)
+	if got, want := d.next(after), time.Date(2026, 1, 8, 8, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("next(%v) = %v, want %v", after, got, want)
+	}
+}
+
+func TestDigestConfig_NextWeekly(t *testing.T) {
+	d := DigestConfig{Schedule: "weekly", Weekday: "Friday"}
+
+	// Wednesday Jan 7 2026 -> Friday Jan 9 at the default 08:00
+	from := time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC)
+	if got, want := d.next(from), time.Date(2026, 1, 9, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got, want)
+	}
+}
+
+func TestDigestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		cfg     DigestConfig
+		wantErr bool
+	}{
+		{DigestConfig{Schedule: "daily"}, false},
+		{DigestConfig{Schedule: "weekly", Weekday: "sunday", Time: "21:00"}, false},
+		{DigestConfig{Schedule: "hourly"}, true},
+		{DigestConfig{Schedule: "daily", Time: "8am"}, true},
+		{DigestConfig{Schedule: "weekly", Weekday: "someday"}, true},
+	}
+	for _, tt := range tests {
+		var errs ConfigErrors
+		if tt.cfg.validate("digest", &errs); (len(errs) > 0) != tt.wantErr {
+			t.Errorf("validate(%+v) errors = %v, wantErr %v", tt.cfg, errs, tt.wantErr)
+		}
+	}
+}
+
+// ===================
+// buildDigest tests
+// ===================
+
+func TestBuildDigest_SummarizesStats(t *testing.T) {
+	started := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	now := started.Add(26 * time.Hour)
+	courses := []CourseStatus{
+		{CRN: "12345", Name: "Data Structures", Open: true, Stats: CourseStats{
+			Checks: 200, Errors: 2, Openings: 2, OpenTime: 10 * time.Minute, OpenSince: now.Add(-5 * time.Minute), Seats: 3,
+		}},
+		{CRN: "67890", Name: "Computer Systems", Stats: CourseStats{Checks: 200, Seats: -1}},
+		{CRN: "11111", Name: "Linear Algebra", Found: true, Stats: CourseStats{Seats: -1}},
+	}
+
+	ev := buildDigest(courses, started, now)
+	body := ev.Body()
+
+	if ev.Kind != EventDigest {
+		t.Errorf("kind = %v, want digest", ev.Kind)
+	}
+	for _, want := range []string{
+		"(1d 2h)",
+		"1 of 3 CRNs still closed",
+		"12345 Data Structures — OPEN",
+		"checks 200, errors 2 (1.0%), opened 2x for 15m, seats 3",
+		"67890 Computer Systems — closed",
+		"seats unknown",
+		"11111 Linear Algebra — done",
+		"Total: 400 checks, 2 errors (0.5%)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("digest missing %q:\n%s", want, body)
+		}
+	}
+}
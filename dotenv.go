@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dotenvFile is the name of the secrets file read at startup
+const dotenvFile = ".env"
+
+// DotenvSource records which variables one .env file supplied
+type DotenvSource struct {
+	Path    string
+	Loaded  []string // variables set from this file
+	Skipped []string // variables already set in the environment or by an earlier file
+}
+
+// dotenvPaths returns the .env files to read: the config file's directory
+// first, then the working directory, without duplicates
+func dotenvPaths(configPath string) []string {
+	var paths []string
+	seen := map[string]bool{}
+	for _, dir := range []string{filepath.Dir(configPath), "."} {
+		path := filepath.Join(dir, dotenvFile)
+		abs, err := filepath.Abs(path)
+		if err != nil || seen[abs] {
+			continue
+		}
+		seen[abs] = true
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// LoadDotenv sets environment variables from each .env file that exists.
+// Variables already in the environment win over files, and earlier files win
+// over later ones.
+func LoadDotenv(paths []string) ([]DotenvSource, error) {
+	var sources []DotenvSource
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return sources, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		vars, err := parseDotenv(string(data))
+		if err != nil {
+			return sources, fmt.Errorf("%s: %w", path, err)
+		}
+
+		src := DotenvSource{Path: path}
+		for _, v := range vars {
+			if _, set := os.LookupEnv(v.key); set {
+				src.Skipped = append(src.Skipped, v.key)
+				continue
+			}
+			if err := os.Setenv(v.key, v.value); err != nil {
+				return sources, fmt.Errorf("%s: %w", path, err)
+			}
+			src.Loaded = append(src.Loaded, v.key)
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// setDotenvVar assigns value to key in the .env file at path, replacing an
+// earlier assignment. A new file is only readable by its owner.
+func setDotenvVar(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+			name, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+			if strings.TrimSpace(name) != key {
+				lines = append(lines, line)
+			}
+		}
+	}
+	quoted := "'" + value + "'"
+	if strings.Contains(value, "'") {
+		quoted = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`).Replace(value) + `"`
+	}
+	lines = append(lines, key+"="+quoted)
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// dotenvVar is one KEY=value assignment
+type dotenvVar struct {
+	key, value string
+}
+
+// parseDotenv reads KEY=value lines. Blank lines and lines starting with # are
+// ignored, as is a leading "export". Values may be bare (a " #" starts a
+// comment), 'single-quoted' (taken literally) or "double-quoted" (supporting
+// \n, \t, \", \\ and \$ escapes, and spanning several lines).
+func parseDotenv(data string) ([]dotenvVar, error) {
+	var vars []dotenvVar
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, rest, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validEnvKey(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNo)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value string
+		switch {
+		case strings.HasPrefix(rest, "'"):
+			end := strings.Index(rest[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", lineNo)
+			}
+			value = rest[1 : end+1]
+		case strings.HasPrefix(rest, `"`):
+			// keep reading lines until the closing quote
+			text := rest[1:]
+			for {
+				v, closed := unquoteDouble(text)
+				if closed {
+					value = v
+					break
+				}
+				if i+1 >= len(lines) {
+					return nil, fmt.Errorf("line %d: unterminated double quote", lineNo)
+				}
+				i++
+				text += "\n" + lines[i]
+			}
+		default:
+			if idx := strings.Index(rest, " #"); idx >= 0 {
+				rest = rest[:idx]
+			}
+			value = strings.TrimSpace(rest)
+		}
+		vars = append(vars, dotenvVar{key: key, value: value})
+	}
+	return vars, nil
+}
+
+// unquoteDouble decodes a double-quoted value up to its closing quote,
+// reporting false if the quote isn't closed
+func unquoteDouble(text string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"':
+			return b.String(), true
+		case c == '\\' && i+1 < len(text):
+			i++
+			switch text[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(text[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(text[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", false
+}
+
+// validEnvKey reports whether key is a usable variable name
+func validEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// ===================
+// .env loading tests
+// ===================
+
+func TestParseDotenv(t *testing.T) {
+	vars, err := parseDotenv(`# Resend
+RESEND_API_KEY=re_abc123   # from the dashboard
+export OPENSEAT_NTFY_TOPIC=vt-seats
+SINGLE='literal \n $HOME # not a comment'
+DOUBLE="line one\nsaid \"hi\" \$5"
+MULTI="first
+second"
+EMPTY=
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []dotenvVar{
+		{"RESEND_API_KEY", "re_abc123"},
+		{"OPENSEAT_NTFY_TOPIC", "vt-seats"},
+		{"SINGLE", `literal \n $HOME # not a comment`},
+		{"DOUBLE", "line one\nsaid \"hi\" $5"},
+		{"MULTI", "first\nsecond"},
+		{"EMPTY", ""},
+	}
+	if !slices.Equal(vars, want) {
+		t.Errorf("parseDotenv =\n%q\nwant\n%q", vars, want)
+	}
+}
+
+func TestParseDotenv_Errors(t *testing.T) {
+	for _, data := range []string{"NOEQUALS", "1BAD=x", "KEY='open", "KEY=\"open\nstill open"} {
+		if _, err := parseDotenv(data); err == nil {
+			t.Errorf("parseDotenv(%q): expected error", data)
+		}
+	}
+}
+
+func TestLoadDotenv_Precedence(t *testing.T) {
+	configDir := t.TempDir()
+	workDir := t.TempDir()
+	os.WriteFile(filepath.Join(configDir, ".env"), []byte("OPENSEAT_TEST_A=config\nOPENSEAT_TEST_B=config\n"), 0o600)
+	os.WriteFile(filepath.Join(workDir, ".env"), []byte("OPENSEAT_TEST_A=work\nOPENSEAT_TEST_C=work\n"), 0o600)
+
+	t.Setenv("OPENSEAT_TEST_B", "process")
+	for _, key := range []string{"OPENSEAT_TEST_A", "OPENSEAT_TEST_C"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+
+	sources, err := LoadDotenv([]string{filepath.Join(configDir, ".env"), filepath.Join(workDir, ".env"), filepath.Join(workDir, "missing.env")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 sources, got %+v", sources)
+	}
+	if got := os.Getenv("OPENSEAT_TEST_A"); got != "config" {
+		t.Errorf("A = %q, want config dir to win", got)
+	}
+	if got := os.Getenv("OPENSEAT_TEST_B"); got != "process" {
+		t.Errorf("B = %q, want the real environment to win", got)
+	}
+	if got := os.Getenv("OPENSEAT_TEST_C"); got != "work" {
+		t.Errorf("C = %q, want working dir value", got)
+	}
+	if !slices.Equal(sources[0].Loaded, []string{"OPENSEAT_TEST_A"}) || !slices.Equal(sources[0].Skipped, []string{"OPENSEAT_TEST_B"}) {
+		t.Errorf("unexpected config dir source: %+v", sources[0])
+	}
+	if !slices.Equal(sources[1].Loaded, []string{"OPENSEAT_TEST_C"}) || !slices.Equal(sources[1].Skipped, []string{"OPENSEAT_TEST_A"}) {
+		t.Errorf("unexpected working dir source: %+v", sources[1])
+	}
+}
+
+func TestDotenvPaths_Dedupes(t *testing.T) {
+	if got := dotenvPaths("config.json"); len(got) != 1 {
+		t.Errorf("config in working dir should give one path, got %v", got)
+	}
+	if got := dotenvPaths("/etc/openseat/config.json"); len(got) != 2 || got[0] != "/etc/openseat/.env" {
+		t.Errorf("dotenvPaths = %v", got)
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAckListen is the local address serving acknowledgement links
+const DefaultAckListen = "127.0.0.1:8787"
+
+// EscalationConfig sends seat openings through progressively louder channels
+// until someone acknowledges them
+type EscalationConfig struct {
+	CRNs    []string         `json:"crns"`    // CRNs that escalate (optional, defaults to all)
+	Steps   []EscalationStep `json:"steps"`   // Channels to alert, in order (required)
+	Listen  string           `json:"listen"`  // Address for acknowledgement links (default 127.0.0.1:8787)
+	BaseURL string           `json:"baseUrl"` // URL the acknowledgement server is reachable at from a phone (defaults to http://<listen>)
+}
+
+// EscalationStep alerts one channel a fixed time after the opening was first seen
+type EscalationStep struct {
+	Channel string `json:"channel"` // Channel name, e.g. "email", "ntfy", "sms" (required)
+	After   int    `json:"after"`   // Seconds after the first alert (default 0)
+}
+
+// validate reports problems with the CRNs, steps and link URL under path, e.g. "escalation"
+func (c EscalationConfig) validate(path string, errs *ConfigErrors) {
+	checkCRNs(c.CRNs, path+".crns", errs)
+	if len(c.Steps) == 0 {
+		errs.add(path+".steps", "needs at least one step")
+	}
+	for i, step := range c.Steps {
+		stepPath := fmt.Sprintf("%s.steps[%d]", path, i)
+		if step.Channel == "" {
+			errs.add(stepPath+".channel", "no channel set")
+		} else {
+			checkChannel(step.Channel, stepPath+".channel", errs)
+		}
+		switch {
+		case step.After < 0:
+			errs.add(stepPath+".after", "must not be negative, got %d", step.After)
+		case i > 0 && step.After < c.Steps[i-1].After:
+			errs.add(stepPath+".after", "must not come before the previous step (%d seconds), got %d", c.Steps[i-1].After, step.After)
+		}
+	}
+	if c.BaseURL != "" {
+		if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add(path+".baseUrl", "must be an http or https URL, got %q", c.BaseURL)
+		}
+	}
+}
+
+// escalation is an opening working its way through the steps
+type escalation struct {
+	id      string
+	event   Event
+	started time.Time
+	next    int // index of the next step to send
+}
+
+// escalationDelivery is a step that is due
+type escalationDelivery struct {
+	Channel string
+	Event   Event
+}
+
+// Escalator tracks unacknowledged openings and decides when each step is due
+type Escalator struct {
+	Config EscalationConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	priority map[string]Priority // per-CRN priorities; high always escalates, low never does
+	active   []*escalation
+}
+
+// NewEscalator creates an escalator for the given config
+func NewEscalator(cfg EscalationConfig) *Escalator {
+	return &Escalator{Config: cfg, now: time.Now}
+}
+
+// setPriorities replaces the per-CRN priorities, e.g. after a reload
+func (e *Escalator) setPriorities(priority map[string]Priority) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.priority = priority
+}
+
+// handles reports whether an event should escalate
+func (e *Escalator) handles(ev Event) bool {
+	if ev.Kind != EventSeatOpen {
+		return false
+	}
+	e.mu.Lock()
+	priority := e.priority[ev.CRN]
+	e.mu.Unlock()
+	switch priority {
+	case PriorityHigh:
+		return true
+	case PriorityLow:
+		return false
+	}
+	return len(e.Config.CRNs) == 0 || slices.Contains(e.Config.CRNs, ev.CRN)
+}
+
+// manages reports whether the escalator, rather than the normal fan-out,
+// delivers this event to the channel
+func (e *Escalator) manages(channel string, ev Event) bool {
+	if !e.handles(ev) {
+		return false
+	}
+	return slices.ContainsFunc(e.Config.Steps, func(s EscalationStep) bool { return s.Channel == channel })
+}
+
+// Start begins escalating an opening and returns it with its acknowledgement
+// link. An opening that is already escalating is not restarted.
+func (e *Escalator) Start(ev Event) Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, esc := range e.active {
+		if esc.event.CRN == ev.CRN {
+			ev.AckURL = esc.event.AckURL
+			return ev
+		}
+	}
+
+	// the token makes the link unguessable, since the server has no other authentication
+	id := ev.CRN + "-" + ackToken()
+	ev.AckURL = e.baseURL() + "/ack/" + id
+	e.active = append(e.active, &escalation{id: id, event: ev, started: e.now()})
+	return ev
+}
+
+// ackToken returns a random token for an acknowledgement link
+func ackToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Due returns the steps whose time has come and advances past them. Openings
+// that have run out of steps stop escalating.
+func (e *Escalator) Due() []escalationDelivery {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	var due []escalationDelivery
+	kept := e.active[:0]
+	for _, esc := range e.active {
+		for esc.next < len(e.Config.Steps) {
+			step := e.Config.Steps[esc.next]
+			if now.Sub(esc.started) < time.Duration(step.After)*time.Second {
+				break
+			}
+			due = append(due, escalationDelivery{Channel: step.Channel, Event: esc.event})
+			esc.next++
+		}
+		if esc.next < len(e.Config.Steps) {
+			kept = append(kept, esc)
+		}
+	}
+	e.active = kept
+	return due
+}
+
+// Pending reports whether any opening still has steps left to send
+func (e *Escalator) Pending() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.active) > 0
+}
+
+// Acknowledge stops escalating openings for crn ("" stops all) and returns how many were stopped
+func (e *Escalator) Acknowledge(crn string) int {
+	return e.remove(func(esc *escalation) bool { return crn == "" || esc.event.CRN == crn })
+}
+
+// acknowledgeID stops the escalation behind an acknowledgement link and returns its CRN
+func (e *Escalator) acknowledgeID(id string) (string, bool) {
+	var crn string
+	stopped := e.remove(func(esc *escalation) bool {
+		if esc.id != id {
+			return false
+		}
+		crn = esc.event.CRN
+		return true
+	})
+	return crn, stopped > 0
+}
+
+// lookupID returns the CRN of the escalation behind an acknowledgement link
+func (e *Escalator) lookupID(id string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, esc := range e.active {
+		if esc.id == id {
+			return esc.event.CRN, true
+		}
+	}
+	return "", false
+}
+
+// remove drops matching escalations and returns how many were dropped
+func (e *Escalator) remove(match func(*escalation) bool) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	before := len(e.active)
+	e.active = slices.DeleteFunc(e.active, match)
+	return before - len(e.active)
+}
+
+// listenAddr returns the address acknowledgement links point at
+func (e *Escalator) listenAddr() string {
+	if e.Config.Listen != "" {
+		return e.Config.Listen
+	}
+	return DefaultAckListen
+}
+
+// baseURL returns the start of acknowledgement links, without a trailing slash
+func (e *Escalator) baseURL() string {
+	if e.Config.BaseURL != "" {
+		return strings.TrimRight(e.Config.BaseURL, "/")
+	}
+	return "http://" + e.listenAddr()
+}
+
+// ackPage asks for confirmation before acknowledging, so link previews and
+// mail scanners that fetch the link don't stop the escalation on their own
+var ackPage = template.Must(template.New("ack").Parse(`<!DOCTYPE html>
+<html>
+<head><meta name="viewport" content="width=device-width, initial-scale=1"><title>Acknowledge alert</title></head>
+<body>
+<p>CRN {{.}} is open and escalating.</p>
+<form method="post"><button type="submit">Acknowledge</button></form>
+</body>
+</html>
+`))
+
+// Handler serves acknowledgement links. Opening a link shows a confirmation
+// page; only submitting it (a POST) acknowledges the alert.
+func (e *Escalator) Handler() http.Handler {
+	return ackHandler(func() []*Escalator { return []*Escalator{e} })
+}
+
+// ackHandler serves acknowledgement links for every escalator the function
+// returns, so profiles can share one server
+func ackHandler(escalators func() []*Escalator) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ack/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, e := range escalators() {
+			if crn, ok := e.lookupID(r.PathValue("id")); ok {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				ackPage.Execute(w, crn)
+				return
+			}
+		}
+		http.Error(w, "This alert was already acknowledged or has finished escalating.", http.StatusNotFound)
+	})
+	mux.HandleFunc("POST /ack/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, e := range escalators() {
+			if crn, ok := e.acknowledgeID(r.PathValue("id")); ok {
+				PrintAcknowledged(crn, "link")
+				fmt.Fprintln(w, "Acknowledged. OpenSeat will stop escalating this alert.")
+				return
+			}
+		}
+		http.Error(w, "This alert was already acknowledged or has finished escalating.", http.StatusNotFound)
+	})
+	return mux
+}
+
+// serveAcks listens on addr and serves acknowledgement links until stop is closed
+func serveAcks(addr string, handler http.Handler, stop <-chan struct{}) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to start acknowledgement server: %w", err)
+	}
+
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-stop
+		server.Shutdown(context.Background())
+	}()
+	if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// Escalation tests
+// ===================
+
+func newTestEscalation(now *time.Time) (*Dispatcher, map[string]*countingNotifier) {
+	channels := map[string]*countingNotifier{
+		"email": {name: "email"},
+		"ntfy":  {name: "ntfy"},
+		"sms":   {name: "sms"},
+	}
+	d := NewDispatcher([]Notifier{channels["email"], channels["ntfy"], channels["sms"]}, AlertPolicy{})
+	d.now = func() time.Time { return *now }
+	d.escalator = NewEscalator(EscalationConfig{
+		CRNs: []string{"12345"},
+		Steps: []EscalationStep{
+			{Channel: "email"},
+			{Channel: "ntfy", After: 120},
+			{Channel: "sms", After: 300},
+		},
+	})
+	d.escalator.now = d.now
+	return d, channels
+}
+
+func TestDispatcher_EscalatesUntilAcknowledged(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	d, ch := newTestEscalation(&now)
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345", Name: "CS-3114"})
+	d.Flush()
+	if ch["email"].sent != 1 || ch["ntfy"].sent != 0 || ch["sms"].sent != 0 {
+		t.Fatalf("after opening: email %d, ntfy %d, sms %d; want 1, 0, 0", ch["email"].sent, ch["ntfy"].sent, ch["sms"].sent)
+	}
+	if !strings.Contains(ch["email"].events[0].Body(), "/ack/") {
+		t.Errorf("expected acknowledgement link in body, got %q", ch["email"].events[0].Body())
+	}
+
+	now = now.Add(2 * time.Minute)
+	d.Escalate()
+	if ch["ntfy"].sent != 1 || ch["sms"].sent != 0 {
+		t.Fatalf("after 2m: ntfy %d, sms %d; want 1, 0", ch["ntfy"].sent, ch["sms"].sent)
+	}
+
+	if n := d.escalator.Acknowledge("12345"); n != 1 {
+		t.Fatalf("Acknowledge stopped %d alerts, want 1", n)
+	}
+	now = now.Add(5 * time.Minute)
+	d.Escalate()
+	if ch["sms"].sent != 0 {
+		t.Errorf("sms sent %d after acknowledgement, want 0", ch["sms"].sent)
+	}
+	if d.Escalating() {
+		t.Error("expected no escalation pending after acknowledgement")
+	}
+}
+
+func TestDispatcher_EscalationRunsToCompletion(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	d, ch := newTestEscalation(&now)
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+	now = now.Add(10 * time.Minute)
+	d.Escalate()
+
+	if ch["ntfy"].sent != 1 || ch["sms"].sent != 1 {
+		t.Errorf("ntfy %d, sms %d; want every step sent once", ch["ntfy"].sent, ch["sms"].sent)
+	}
+	if d.Escalating() {
+		t.Error("expected escalation to finish after the last step")
+	}
+}
+
+func TestDispatcher_OtherCRNsDoNotEscalate(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	d, ch := newTestEscalation(&now)
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	d.Flush()
+
+	for name, n := range ch {
+		if n.sent != 1 {
+			t.Errorf("%s sent %d, want 1 (normal fan-out)", name, n.sent)
+		}
+	}
+	if d.Escalating() {
+		t.Error("unlisted CRN should not escalate")
+	}
+}
+
+func TestDispatcher_SeatClosedStopsEscalation(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	d, _ := newTestEscalation(&now)
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Dispatch(Event{Kind: EventSeatClosed, CRN: "12345"})
+
+	if d.Escalating() {
+		t.Error("expected closing to stop escalation")
+	}
+}
+
+func TestEscalator_AcknowledgeLink(t *testing.T) {
+	e := NewEscalator(EscalationConfig{Steps: []EscalationStep{{Channel: "sms", After: 60}}})
+	ev := e.Start(Event{Kind: EventSeatOpen, CRN: "12345"})
+
+	server := httptest.NewServer(e.Handler())
+	defer server.Close()
+	path := ev.AckURL[strings.Index(ev.AckURL, "/ack/"):]
+
+	// opening the link (as a link preview would) only shows the confirmation page
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(page), `method="post"`) {
+		t.Errorf("GET status = %d, body %q; want a confirmation form", resp.StatusCode, page)
+	}
+	if !e.Pending() {
+		t.Fatal("opening the link should not acknowledge the alert")
+	}
+
+	resp, err = http.Post(server.URL+path, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("first ack status = %d, want 200", resp.StatusCode)
+	}
+	if e.Pending() {
+		t.Error("expected confirming to acknowledge the alert")
+	}
+
+	resp, err = http.Post(server.URL+path, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("repeat ack status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestEscalator_AcknowledgeLinkIsUnguessable(t *testing.T) {
+	e := NewEscalator(EscalationConfig{Steps: []EscalationStep{{Channel: "sms"}}, BaseURL: "https://seats.example.com/"})
+	ev := e.Start(Event{Kind: EventSeatOpen, CRN: "12345"})
+	if !strings.HasPrefix(ev.AckURL, "https://seats.example.com/ack/12345-") || len(ev.AckURL) < len("https://seats.example.com/ack/12345-")+32 {
+		t.Errorf("AckURL = %q, want the base URL and a random token", ev.AckURL)
+	}
+
+	server := httptest.NewServer(e.Handler())
+	defer server.Close()
+	for _, guess := range []string{"12345-1", "12345-0"} {
+		resp, err := http.Post(server.URL+"/ack/"+guess, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("guessed id %s acknowledged the alert", guess)
+		}
+	}
+	if !e.Pending() {
+		t.Error("expected the alert to still be escalating")
+	}
+}
+
+func TestEscalationConfig_Validate(t *testing.T) {
+	bad := []EscalationConfig{
+		{},
+		{Steps: []EscalationStep{{After: 10}}},
+		{Steps: []EscalationStep{{Channel: "ntfy", After: 120}, {Channel: "sms", After: 60}}},
+		{Steps: []EscalationStep{{Channel: "sms"}}, BaseURL: "seats.example.com"},
+		{Steps: []EscalationStep{{Channel: "pager"}}},
+	}
+	for i, cfg := range bad {
+		var errs ConfigErrors
+		if cfg.validate("escalation", &errs); len(errs) == 0 {
+			t.Errorf("config %d: expected validation error", i)
+		}
+	}
+}
+
+func TestHandleCommand_Ack(t *testing.T) {
+	monitor := NewMonitor(Config{}, []CourseStatus{{CRN: "12345", Name: "Intro to Testing"}})
+	escalator := NewEscalator(EscalationConfig{Steps: []EscalationStep{{Channel: "sms", After: 60}}})
+	monitor.escalator = escalator
+
+	if reply := handleCommand("/ack", monitor); !strings.Contains(reply, "No alerts") {
+		t.Errorf("/ack with nothing escalating = %q", reply)
+	}
+	escalator.Start(Event{Kind: EventSeatOpen, CRN: "12345"})
+	if reply := handleCommand("/ack 12345", monitor); !strings.Contains(reply, "Acknowledged") {
+		t.Errorf("/ack reply = %q", reply)
+	}
+	if escalator.Pending() {
+		t.Error("expected /ack to stop escalation")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExecConfig configures a user script that runs on monitor events
+type ExecConfig struct {
+	Command        string   `json:"command"`        // Shell command to run (required)
+	Events         []string `json:"events"`         // Events that trigger the hook: seat_open, seat_closed (continuous mode), error (default all)
+	Timeout        int      `json:"timeout"`        // Seconds before the command is killed (default 30)
+	ErrorThreshold int      `json:"errorThreshold"` // Consecutive failures before an error triggers the hook (default 3)
+}
+
+// ExecNotifier runs a command for each event, passing details through
+// OPENSEAT_* environment variables and as JSON on stdin
+type ExecNotifier struct {
+	Config  ExecConfig
+	hidden  []string // variables named by the config's env: references
+	secrets []string // the config's resolved secrets, hidden under any variable name
+}
+
+// newExecNotifier creates the hook channel for cfg, which must have its secrets resolved
+func newExecNotifier(cfg Config) *ExecNotifier {
+	n := &ExecNotifier{Config: *cfg.Exec, hidden: cfg.secretEnv}
+	for _, s := range configSecrets(&cfg) {
+		if value := s.Value.String(); value != "" {
+			n.secrets = append(n.secrets, value)
+		}
+	}
+	return n
+}
+
+func (n *ExecNotifier) Name() string { return "exec" }
+
+// PrefersUrgent runs the hook once per CRN so scripts always see a single OPENSEAT_CRN
+func (n *ExecNotifier) PrefersUrgent() bool { return true }
+
+// RunsInBackground keeps a slow script from holding up the next check
+func (n *ExecNotifier) RunsInBackground() bool { return true }
+
+// Accepts filters to the configured events. Errors only trigger the hook once
+// per failure streak, when it reaches the threshold.
+func (n *ExecNotifier) Accepts(ev Event) bool {
+	if len(n.Config.Events) > 0 && !slices.Contains(n.Config.Events, ev.Kind.String()) {
+		return false
+	}
+	if ev.Kind == EventError {
+		return reachesErrorThreshold(ev, n.Config.ErrorThreshold)
+	}
+	return true
+}
+
+func (n *ExecNotifier) Notify(ev Event) error {
+	if n.Config.Command == "" {
+		return fmt.Errorf("exec command not set")
+	}
+
+	timeout := time.Duration(n.Config.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	input, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	cmd := shellCommand(ctx, n.Config.Command)
+	cmd.Env = append(n.inheritedEnv(), hookEnv(ev)...)
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// don't wait on grandchildren that still hold stderr open after a timeout kill
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("exit status %d: %s", exitErr.ExitCode(), lastLine(stderr.String()))
+		}
+		return fmt.Errorf("failed to run command: %w", err)
+	}
+	if stderr.Len() > 0 {
+		PrintHookOutput(ev.Kind.String(), lastLine(stderr.String()))
+	}
+	return nil
+}
+
+// shellCommand runs command through the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// inheritedEnv returns the monitor's environment minus anything that holds a
+// secret: RESEND_API_KEY, the OPENSEAT_* settings (replaced by the event's own
+// variables), the targets of env: references and variables set to a secret
+func (n *ExecNotifier) inheritedEnv() []string {
+	hidden := append([]string{"RESEND_API_KEY"}, n.hidden...)
+	var env []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(strings.ToUpper(name), envPrefix+"_") ||
+			slices.ContainsFunc(hidden, func(h string) bool { return strings.EqualFold(h, name) }) ||
+			slices.Contains(n.secrets, value) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// hookEnv returns the OPENSEAT_* environment variables describing an event
+func hookEnv(ev Event) []string {
+	env := []string{
+		"OPENSEAT_EVENT=" + ev.Kind.String(),
+		"OPENSEAT_CRN=" + ev.CRN,
+		"OPENSEAT_NAME=" + ev.Name,
+		"OPENSEAT_MESSAGE=" + ev.Body(),
+		"OPENSEAT_TIME=" + ev.Time.Format(time.RFC3339),
+		"OPENSEAT_FAILURES=" + strconv.Itoa(ev.Failures),
+		"OPENSEAT_REGISTRATION_URL=" + RegistrationURL,
+	}
+	if s := ev.Section; s != nil {
+		if s.Seats >= 0 {
+			env = append(env, "OPENSEAT_SEATS="+strconv.Itoa(s.Seats))
+		}
+		if s.Capacity >= 0 {
+			env = append(env, "OPENSEAT_CAPACITY="+strconv.Itoa(s.Capacity))
+		}
+		env = append(env,
+			"OPENSEAT_COURSE="+s.Course,
+			"OPENSEAT_INSTRUCTOR="+s.Instructor,
+			"OPENSEAT_MEETING="+s.MeetingTime(),
+			"OPENSEAT_LOCATION="+s.Location,
+		)
+	}
+	return env
+}
+
+// lastLine returns the last non-empty line of command output for compact error display
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// ExecNotifier tests
+// ===================
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use /bin/sh")
+	}
+}
+
+func TestExecNotifier_PassesEnvAndStdin(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "env")
+	stdinFile := filepath.Join(dir, "stdin")
+
+	n := &ExecNotifier{Config: ExecConfig{
+		Command: `echo "$OPENSEAT_EVENT $OPENSEAT_CRN $OPENSEAT_SEATS" > ` + envFile + ` && cat > ` + stdinFile,
+	}}
+	ev := Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing", Section: &Section{CRN: "12345", Seats: 3, Capacity: 30}}
+	if err := n.Notify(ev); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	env, _ := os.ReadFile(envFile)
+	if got := strings.TrimSpace(string(env)); got != "seat_open 12345 3" {
+		t.Errorf("env = %q, want %q", got, "seat_open 12345 3")
+	}
+
+	var decoded struct {
+		Event   string `json:"event"`
+		CRN     string `json:"crn"`
+		Section struct {
+			Seats int `json:"seats"`
+		} `json:"section"`
+	}
+	data, _ := os.ReadFile(stdinFile)
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("stdin is not JSON: %v (%s)", err, data)
+	}
+	if decoded.Event != "seat_open" || decoded.CRN != "12345" || decoded.Section.Seats != 3 {
+		t.Errorf("unexpected stdin payload: %s", data)
+	}
+}
+
+func TestExecNotifier_DoesNotLeakEnvironment(t *testing.T) {
+	skipOnWindows(t)
+	t.Setenv("OPENSEAT_TELEGRAM_TOKEN", "123:secret")
+	t.Setenv("RESEND_API_KEY", "re_secret")
+	t.Setenv("NTFY_TOKEN", "tk_secret")
+	t.Setenv("GOTIFY_COPY", "inline-secret")
+	t.Setenv("SCHOOL_NAME", "Virginia Tech")
+	out := filepath.Join(t.TempDir(), "env")
+
+	cfg := Config{
+		Exec:   &ExecConfig{Command: "env > " + out},
+		Ntfy:   &NtfyConfig{Topic: "seats", Token: "env:NTFY_TOKEN"},
+		Gotify: &GotifyConfig{Server: "https://push.example.com", Token: "inline-secret"},
+	}
+	if errs := resolveSecrets(&cfg, os.LookupEnv); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	n := newExecNotifier(cfg)
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	env, _ := os.ReadFile(out)
+	if strings.Contains(string(env), "secret") {
+		t.Errorf("hook saw the monitor's secrets:\n%s", env)
+	}
+	for _, want := range []string{"OPENSEAT_CRN=12345", "PATH=", "SCHOOL_NAME=Virginia Tech"} {
+		if !strings.Contains(string(env), want) {
+			t.Errorf("hook is missing %s:\n%s", want, env)
+		}
+	}
+}
+
+func TestDispatcher_RunsHooksInBackground(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	release := filepath.Join(dir, "release")
+	n := &ExecNotifier{Config: ExecConfig{Command: "while [ ! -e " + release + " ]; do sleep 0.05; done; echo ran >> " + filepath.Join(dir, "runs")}}
+	d := NewDispatcher([]Notifier{n}, AlertPolicy{})
+	now := time.Now()
+	d.outbox = openTestOutbox(t, &now)
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.RetryDue() // the entry is still in flight and mustn't run twice
+	if _, err := os.Stat(filepath.Join(dir, "runs")); err == nil {
+		t.Fatal("Dispatch waited for the hook")
+	}
+
+	os.WriteFile(release, nil, 0o600)
+	d.Wait()
+	if runs, _ := os.ReadFile(filepath.Join(dir, "runs")); string(runs) != "ran\n" {
+		t.Errorf("expected the hook to finish once before Wait returned, got %q", runs)
+	}
+	if entries := d.outbox.Entries(); len(entries) != 0 {
+		t.Errorf("expected the delivered hook removed from the outbox, got %+v", entries)
+	}
+}
+
+func TestExecNotifier_ReportsExitStatusAndStderr(t *testing.T) {
+	skipOnWindows(t)
+	n := &ExecNotifier{Config: ExecConfig{Command: "echo 'webhook down' >&2; exit 3"}}
+
+	err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345"})
+	if err == nil || err.Error() != "exit status 3: webhook down" {
+		t.Errorf("got %v, want exit status 3 with stderr", err)
+	}
+}
+
+func TestExecNotifier_Timeout(t *testing.T) {
+	skipOnWindows(t)
+	n := &ExecNotifier{Config: ExecConfig{Command: "sleep 5", Timeout: 1}}
+
+	err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345"})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestExecNotifier_AcceptsPersistentErrorsOnce(t *testing.T) {
+	n := &ExecNotifier{Config: ExecConfig{ErrorThreshold: 2}}
+
+	if n.Accepts(Event{Kind: EventError, Failures: 1}) {
+		t.Error("expected first failure to be ignored")
+	}
+	if !n.Accepts(Event{Kind: EventError, Failures: 2}) {
+		t.Error("expected failure at threshold to be accepted")
+	}
+	if n.Accepts(Event{Kind: EventError, Failures: 3}) {
+		t.Error("expected later failures in the same streak to be ignored")
+	}
+}
+
+func TestExecNotifier_AcceptsConfiguredEvents(t *testing.T) {
+	n := &ExecNotifier{Config: ExecConfig{Events: []string{"seat_closed"}}}
+
+	if n.Accepts(Event{Kind: EventSeatOpen}) {
+		t.Error("expected seat_open to be filtered out")
+	}
+	if !n.Accepts(Event{Kind: EventSeatClosed}) {
+		t.Error("expected seat_closed to be accepted")
+	}
+}
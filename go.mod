@@ -3,8 +3,15 @@ module github.com/brennanhumphrey/openseat
 go 1.25.6
 
 require (
-	github.com/PuerkitoBio/goquery v1.11.0 // indirect
+	github.com/BurntSushi/toml v1.6.0
+	github.com/PuerkitoBio/goquery v1.11.0
+	github.com/godbus/dbus/v5 v5.2.2
+	github.com/resend/resend-go/v2 v2.28.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/andybalholm/cascadia v1.3.3 // indirect
-	github.com/resend/resend-go/v2 v2.28.0 // indirect
 	golang.org/x/net v0.47.0 // indirect
+	golang.org/x/sys v0.38.0 // indirect
 )
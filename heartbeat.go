@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HeartbeatConfig configures liveness reporting for the monitor itself
+type HeartbeatConfig struct {
+	URL              Secret `json:"url"`              // Ping URL, e.g. a healthchecks.io check; the check's key is part of it (optional)
+	Interval         int    `json:"interval"`         // Seconds between pings (default 300)
+	FailureThreshold int    `json:"failureThreshold"` // Consecutive failed checks across all CRNs before alerting (default 20)
+	NotifyOnExit     bool   `json:"notifyOnExit"`     // Send a final notification when the monitor exits for any reason
+}
+
+// Heartbeat pings an external dead-man's switch while the monitoring loop is
+// healthy and raises an alert when every check has been failing
+type Heartbeat struct {
+	Config HeartbeatConfig
+
+	lastPing  time.Time
+	unhealthy bool // an unhealthy alert was sent and checks haven't recovered yet
+}
+
+// Tick is called once per monitoring cycle with the current run of failed checks.
+// It pings the URL when due (see Ping) and returns an unhealthy event the first time the
+// failure streak reaches the threshold.
+func (h *Heartbeat) Tick(now time.Time, failStreak int) *Event {
+	threshold := h.Config.FailureThreshold
+	if threshold == 0 {
+		threshold = 20
+	}
+
+	var alert *Event
+	switch {
+	case failStreak >= threshold && !h.unhealthy:
+		h.unhealthy = true
+		alert = &Event{
+			Kind:     EventUnhealthy,
+			Time:     now,
+			Failures: failStreak,
+			Message:  fmt.Sprintf("The last %d timetable checks all failed. OpenSeat may have lost network access.", failStreak),
+		}
+		// tell the dead-man's switch right away instead of waiting for the next ping
+		h.ping("/fail", now)
+	case failStreak == 0 && h.unhealthy:
+		h.unhealthy = false
+		PrintHealthRecovered()
+	}
+
+	h.Ping(now)
+	return alert
+}
+
+// Ping pings the URL if the interval has passed since the last one. The
+// monitor calls it while waiting between checks and while paused too, so a
+// checkInterval longer than the heartbeat interval doesn't make pings late.
+func (h *Heartbeat) Ping(now time.Time) {
+	interval := time.Duration(h.Config.Interval) * time.Second
+	if interval == 0 {
+		interval = 5 * time.Minute
+	}
+	if now.Sub(h.lastPing) >= interval {
+		suffix := ""
+		if h.unhealthy {
+			suffix = "/fail"
+		}
+		h.ping(suffix, now)
+	}
+}
+
+// ping requests the heartbeat URL with an optional healthchecks-style suffix
+func (h *Heartbeat) ping(suffix string, now time.Time) {
+	if h.Config.URL == "" {
+		return
+	}
+	h.lastPing = now
+
+	// errors quote the URL, which holds the check's key
+	base := Secret(strings.TrimRight(h.Config.URL.Value(), "/"))
+	req, err := http.NewRequest(http.MethodGet, base.Value()+suffix, nil)
+	if err != nil {
+		PrintHeartbeatError(hideSecret(err, base))
+		return
+	}
+	if err := doNotifyRequest(req); err != nil {
+		PrintHeartbeatError(hideSecret(err, base))
+	}
+}
+
+// exitEvent builds the final notification sent when the monitor stops
+func exitEvent(reason string) Event {
+	return Event{Kind: EventShutdown, Time: time.Now(), Message: "OpenSeat stopped: " + reason}
+}
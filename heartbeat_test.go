@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ===================
+// Heartbeat tests
+// ===================
+
+func newPingServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func TestHeartbeat_PingsOnInterval(t *testing.T) {
+	server, pings := newPingServer(t)
+	defer server.Close()
+
+	h := &Heartbeat{Config: HeartbeatConfig{URL: Secret(server.URL + "/ping/abc"), Interval: 60}}
+	now := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+
+	h.Tick(now, 0)
+	h.Tick(now.Add(30*time.Second), 0)
+	h.Tick(now.Add(61*time.Second), 0)
+
+	if got := pings(); len(got) != 2 || got[0] != "/ping/abc" {
+		t.Errorf("pings = %v, want 2 pings to /ping/abc", got)
+	}
+}
+
+func TestHeartbeat_PingsBetweenChecks(t *testing.T) {
+	server, pings := newPingServer(t)
+	defer server.Close()
+
+	// checks every 10 minutes, pings every minute
+	h := &Heartbeat{Config: HeartbeatConfig{URL: Secret(server.URL), Interval: 60}}
+	now := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+
+	h.Tick(now, 0)
+	for s := 10; s < 600; s += 10 {
+		h.Ping(now.Add(time.Duration(s) * time.Second))
+	}
+	h.Tick(now.Add(10*time.Minute), 0)
+
+	if got := pings(); len(got) != 11 {
+		t.Errorf("got %d pings over 10 minutes, want one a minute: %v", len(got), got)
+	}
+}
+
+func TestHeartbeat_AlertsOnceWhenFailuresPassThreshold(t *testing.T) {
+	server, pings := newPingServer(t)
+	defer server.Close()
+
+	h := &Heartbeat{Config: HeartbeatConfig{URL: Secret(server.URL), Interval: 3600, FailureThreshold: 5}}
+	now := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+
+	if alert := h.Tick(now, 4); alert != nil {
+		t.Fatal("expected no alert below threshold")
+	}
+	alert := h.Tick(now.Add(time.Minute), 5)
+	if alert == nil || alert.Kind != EventUnhealthy || alert.Failures != 5 {
+		t.Fatalf("expected unhealthy alert at threshold, got %+v", alert)
+	}
+	if alert := h.Tick(now.Add(2*time.Minute), 6); alert != nil {
+		t.Error("expected a single alert per outage")
+	}
+
+	got := pings()
+	if len(got) != 2 || got[1] != "/fail" {
+		t.Errorf("pings = %v, want regular ping then /fail", got)
+	}
+
+	// recovery re-arms the alert
+	h.Tick(now.Add(3*time.Minute), 0)
+	if alert := h.Tick(now.Add(4*time.Minute), 5); alert == nil {
+		t.Error("expected a new alert after recovering and failing again")
+	}
+}
+
+func TestHeartbeat_NoURLStillAlerts(t *testing.T) {
+	h := &Heartbeat{Config: HeartbeatConfig{FailureThreshold: 1}}
+	if alert := h.Tick(time.Now(), 1); alert == nil {
+		t.Error("expected failure alert without a ping URL")
+	}
+}
@@ -0,0 +1,491 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultInitPath is where `openseat init` writes the config. YAML is used so
+// the file can carry comments explaining each setting.
+const DefaultInitPath = "config.yaml"
+
+// courseSearchPattern matches a course typed into the wizard, e.g. "CS 3114" or "math-1226"
+var courseSearchPattern = regexp.MustCompile(`^([A-Za-z]{2,5})[\s-]*([0-9]{4})$`)
+
+// timetableOption is one choice from a select box on the timetable search form
+type timetableOption struct {
+	Value string
+	Label string
+}
+
+// timetableFormURL returns the page with the search form for a results URL
+func timetableFormURL(baseURL string) string {
+	return strings.Replace(baseURL, "P_ProcRequest", "P_DispRequest", 1)
+}
+
+// fetchTimetableOptions reads the terms and campuses offered by the timetable search form
+func fetchTimetableOptions(baseURL string) (terms, campuses []timetableOption, err error) {
+	resp, err := http.Get(timetableFormURL(baseURL))
+	if err != nil {
+		return nil, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status: %d %s", resp.StatusCode, resp.Status)
+	}
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	options := func(name string) []timetableOption {
+		var opts []timetableOption
+		doc.Find(fmt.Sprintf("select[name=%q] option", name)).Each(func(i int, opt *goquery.Selection) {
+			value := strings.TrimSpace(opt.AttrOr("value", ""))
+			if value == "" || value == "%" {
+				return
+			}
+			opts = append(opts, timetableOption{Value: value, Label: strings.Join(strings.Fields(opt.Text()), " ")})
+		})
+		return opts
+	}
+	terms, campuses = options("TERMYEAR"), options("CAMPUS")
+	if len(terms) == 0 {
+		return nil, nil, fmt.Errorf("no terms found on the timetable search form")
+	}
+	return terms, campuses, nil
+}
+
+// initTokenVar is the .env variable the wizard keeps a Telegram bot token in
+const initTokenVar = "TELEGRAM_BOT_TOKEN"
+
+// initWizard asks the questions for `openseat init` and builds a config from the answers
+type initWizard struct {
+	in          *bufio.Scanner
+	out         io.Writer
+	baseURL     string
+	emailSender EmailSender
+	dotenv      string // .env file that typed-in secrets are saved to
+}
+
+// RunInitCommand handles `openseat init [output] [--force]`: an interactive
+// wizard that writes a commented config file
+func RunInitCommand(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.SetOutput(out)
+	force := fs.Bool("force", false, "overwrite an existing config file")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Usage: openseat init [output] [--force]")
+		fmt.Fprintf(out, "Writes %s unless another path is given; .json, .yaml, .yml and .toml are supported.\n", DefaultInitPath)
+		fs.PrintDefaults()
+	}
+	paths, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(paths) > 1 {
+		fs.Usage()
+		return fmt.Errorf("expected at most one output file")
+	}
+	output := DefaultInitPath
+	if len(paths) == 1 {
+		output = paths[0]
+	}
+	if _, err := os.Stat(output); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", output)
+	}
+
+	// .env values such as RESEND_API_KEY are needed for the test notification
+	dotenv := dotenvPaths(output)
+	if _, err := LoadDotenv(dotenv); err != nil {
+		return err
+	}
+
+	w := &initWizard{
+		in:          bufio.NewScanner(in),
+		out:         out,
+		baseURL:     DefaultTimetableURL,
+		emailSender: &ResendEmailSender{APIKey: os.Getenv("RESEND_API_KEY")},
+		dotenv:      dotenv[0],
+	}
+	cfg, err := w.run()
+	if err != nil {
+		return err
+	}
+	if err := w.saveSecrets(&cfg); err != nil {
+		return err
+	}
+
+	data, err := writeCommentedConfig(cfg, formatForPath(output))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Fprintf(out, "\nWrote %s\n", output)
+	if output == DefaultConfigPath {
+		fmt.Fprintln(out, "Start monitoring with: openseat")
+	} else {
+		fmt.Fprintf(out, "Start monitoring with: openseat --config %s\n", output)
+	}
+	return nil
+}
+
+// run asks every question and returns the resulting config
+func (w *initWizard) run() (Config, error) {
+	cfg := Config{BaseURL: w.baseURL, CheckInterval: 30}
+	fmt.Fprintln(w.out, "OpenSeat setup. Press Enter to accept the [default].")
+
+	terms, campuses, err := fetchTimetableOptions(w.baseURL)
+	if err != nil {
+		fmt.Fprintf(w.out, "Couldn't load terms from the timetable (%v); enter codes by hand.\n", err)
+	}
+	term, err := w.choose("Term", terms, "next", func(s string) (string, error) {
+		term, err := ParseTerm(s, time.Now())
+		return string(term), err
+	})
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Term = Term(term)
+	if cfg.Campus, err = w.choose("Campus", campuses, "0", func(s string) (string, error) {
+		if !campusPattern.MatchString(s) {
+			return "", fmt.Errorf("%q is not a campus code", s)
+		}
+		return s, nil
+	}); err != nil {
+		return Config{}, err
+	}
+	if cfg.CRNs, err = w.askCourses(cfg); err != nil {
+		return Config{}, err
+	}
+	if err := w.askChannels(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	interval, err := w.ask("Seconds between checks", strconv.Itoa(cfg.CheckInterval))
+	if err != nil {
+		return Config{}, err
+	}
+	if cfg.CheckInterval, err = strconv.Atoi(interval); err != nil || cfg.CheckInterval < minCheckInterval {
+		fmt.Fprintf(w.out, "Using 30 seconds; the interval must be a number of at least %d.\n", minCheckInterval)
+		cfg.CheckInterval = 30
+	}
+
+	if errs := cfg.validate(); len(errs) > 0 {
+		return Config{}, errs
+	}
+	if len(buildNotifiers(cfg, w.emailSender)) > 0 {
+		if yes, err := w.confirm("Send a test notification now?", true); err != nil {
+			return Config{}, err
+		} else if yes {
+			w.sendTest(cfg)
+		}
+	}
+	cfg.BaseURL = ""
+	return cfg, nil
+}
+
+// ask prints a question and returns the trimmed answer, or def for a blank one
+func (w *initWizard) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	if !w.in.Scan() {
+		if err := w.in.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("setup cancelled")
+	}
+	if answer := strings.TrimSpace(w.in.Text()); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// confirm asks a yes/no question
+func (w *initWizard) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := w.ask(question+" ("+hint+")", "")
+	if err != nil || answer == "" {
+		return def, err
+	}
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+// choose offers numbered options, also accepting a value typed directly that
+// parse accepts. A number that is itself one of the values picks that value;
+// other numbers pick from the list. With no options any value parse accepts will do.
+func (w *initWizard) choose(what string, options []timetableOption, def string, parse func(string) (string, error)) (string, error) {
+	if len(options) > 0 {
+		fmt.Fprintf(w.out, "\n%s:\n", what)
+		for i, opt := range options {
+			fmt.Fprintf(w.out, "  %d) %s (%s)\n", i+1, opt.Label, opt.Value)
+		}
+		def = options[0].Value
+	}
+	for {
+		answer, err := w.ask(what, def)
+		if err != nil {
+			return "", err
+		}
+		value, err := parse(answer)
+		if err == nil && (len(options) == 0 || slices.ContainsFunc(options, func(o timetableOption) bool { return o.Value == value })) {
+			return value, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1].Value, nil
+		}
+		if err != nil {
+			fmt.Fprintf(w.out, "  %v\n", err)
+		} else {
+			fmt.Fprintf(w.out, "  %q isn't one of the choices.\n", answer)
+		}
+	}
+}
+
+// askCourses collects CRNs, checking each against the timetable. Typing a
+// course like "CS 3114" lists its sections to pick from.
+func (w *initWizard) askCourses(cfg Config) (WatchList, error) {
+	fmt.Fprintln(w.out, "\nEnter CRNs (e.g. 12345) or search for a course (e.g. CS 3114). Leave blank when done.")
+	var crns []string
+	add := func(crn, name string) {
+		if slices.Contains(crns, crn) {
+			fmt.Fprintf(w.out, "  %s is already on the list\n", crn)
+			return
+		}
+		crns = append(crns, crn)
+		fmt.Fprintf(w.out, "  Added %s: %s\n", crn, name)
+	}
+
+	for {
+		answer, err := w.ask("CRN or course", "")
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case answer == "" && len(crns) > 0:
+			return newWatchList(crns...), nil
+		case answer == "":
+			fmt.Fprintln(w.out, "  Add at least one CRN.")
+		case crnPattern.MatchString(answer):
+			name, err := cfg.getCourseName(answer)
+			if err != nil {
+				fmt.Fprintf(w.out, "  %s wasn't found in the timetable for this term: %v\n", answer, err)
+				continue
+			}
+			add(answer, name)
+		case courseSearchPattern.MatchString(answer):
+			m := courseSearchPattern.FindStringSubmatch(answer)
+			sections, err := cfg.searchSections(m[1], m[2])
+			if err != nil {
+				fmt.Fprintf(w.out, "  Search failed: %v\n", err)
+				continue
+			}
+			if len(sections) == 0 {
+				fmt.Fprintf(w.out, "  No sections of %s %s this term.\n", strings.ToUpper(m[1]), m[2])
+				continue
+			}
+			for i, s := range sections {
+				fmt.Fprintf(w.out, "  %d) %s %s %s", i+1, s.CRN, s.Course, s.Title)
+				if meeting := s.MeetingTime(); meeting != "" {
+					fmt.Fprintf(w.out, " - %s", meeting)
+				}
+				if s.Instructor != "" {
+					fmt.Fprintf(w.out, " - %s", s.Instructor)
+				}
+				fmt.Fprintln(w.out)
+			}
+			picks, err := w.ask("Sections to watch (numbers separated by spaces, blank for none)", "")
+			if err != nil {
+				return nil, err
+			}
+			for _, pick := range strings.Fields(strings.ReplaceAll(picks, ",", " ")) {
+				n, err := strconv.Atoi(pick)
+				if err != nil || n < 1 || n > len(sections) {
+					fmt.Fprintf(w.out, "  Skipping %q, not a section number\n", pick)
+					continue
+				}
+				add(sections[n-1].CRN, sections[n-1].Title)
+			}
+		default:
+			fmt.Fprintf(w.out, "  %q is neither a CRN (5 digits) nor a course like CS 3114.\n", answer)
+		}
+	}
+}
+
+// askChannels sets up the notification channels the user wants
+func (w *initWizard) askChannels(cfg *Config) error {
+	fmt.Fprintln(w.out, "\nNotifications (leave blank to skip a channel):")
+	for {
+		email, err := w.ask("Email address (sent with Resend, needs RESEND_API_KEY)", "")
+		if err != nil {
+			return err
+		}
+		if addr, err := mail.ParseAddress(email); email != "" && (err != nil || addr.Address != email) {
+			fmt.Fprintf(w.out, "  %q is not a valid email address.\n", email)
+			continue
+		}
+		cfg.Email = email
+		break
+	}
+	topic, err := w.ask("ntfy topic", "")
+	if err != nil {
+		return err
+	}
+	if topic != "" {
+		cfg.Ntfy = &NtfyConfig{Topic: topic}
+	}
+	tokenDefault := ""
+	if _, ok := os.LookupEnv(initTokenVar); ok {
+		tokenDefault = "env:" + initTokenVar
+	}
+	token, err := w.ask("Telegram bot token", tokenDefault)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		chatID, err := w.ask("Telegram chat ID", "")
+		if err != nil {
+			return err
+		}
+		cfg.Telegram = &TelegramConfig{Token: Secret(token), ChatID: chatID}
+	}
+	desktop, err := w.confirm("Desktop notifications?", false)
+	if err != nil {
+		return err
+	}
+	if desktop {
+		cfg.Desktop = &DesktopConfig{Notify: true, Bell: true, Title: true}
+	}
+	return nil
+}
+
+// sendTest sends a test message on every configured channel and reports each result
+func (w *initWizard) sendTest(cfg Config) {
+	// references such as env:TELEGRAM_BOT_TOKEN are resolved for the test only
+	if cfg.Telegram != nil {
+		telegram := *cfg.Telegram
+		cfg.Telegram = &telegram
+	}
+	if errs := resolveSecrets(&cfg, os.LookupEnv); len(errs) > 0 {
+		fmt.Fprintf(w.out, "  Skipping the test: %v\n", errs)
+		return
+	}
+
+	ev := Event{
+		Kind:    EventDigest,
+		Time:    time.Now(),
+		Message: "This is a test from openseat init. Alerts for your CRNs will arrive here.",
+		title:   "OpenSeat test notification",
+	}
+	for _, n := range buildNotifiers(cfg, w.emailSender) {
+		if err := n.Notify(ev); err != nil {
+			fmt.Fprintf(w.out, "  %s: failed: %v\n", n.Name(), err)
+			continue
+		}
+		fmt.Fprintf(w.out, "  %s: sent\n", n.Name())
+	}
+}
+
+// saveSecrets moves a bot token typed into the wizard to the .env file and
+// points the config at it, so the config file doesn't hold the secret
+func (w *initWizard) saveSecrets(cfg *Config) error {
+	if cfg.Telegram == nil {
+		return nil
+	}
+	if _, _, ok := cfg.Telegram.Token.reference(); ok {
+		return nil
+	}
+	if err := setDotenvVar(w.dotenv, initTokenVar, cfg.Telegram.Token.Value()); err != nil {
+		return err
+	}
+	cfg.Telegram.Token = Secret("env:" + initTokenVar)
+	fmt.Fprintf(w.out, "\nSaved the bot token to %s as %s\n", w.dotenv, initTokenVar)
+	return nil
+}
+
+// configComments explains each setting the wizard writes, keyed by config key
+var configComments = map[string]string{
+	"term":          "Term code (202601 is Spring 2026), or a name like \"Fall 2026\", \"current\" or \"next\"",
+	"campus":        "Campus code (0 = Blacksburg)",
+	"checkInterval": "Seconds between checks (minimum 10)",
+	"crns":          "CRNs to watch. Each can also be an object with a label, priority, minSeats, channels or expires.",
+	"email":         "Email alerts are sent with Resend; set RESEND_API_KEY in the environment or a .env file",
+	"ntfy":          "ntfy push notifications; subscribe to the topic in the ntfy app",
+	"telegram":      "Telegram bot alerts; set commands: true to control the monitor from the chat",
+	"desktop":       "Desktop notifications and terminal signals",
+}
+
+// configKeyOrder lists the keys the wizard writes, settings before sections
+// (TOML requires plain keys before tables)
+var configKeyOrder = []string{"term", "campus", "checkInterval", "crns", "email", "ntfy", "telegram", "desktop"}
+
+// writeCommentedConfig renders cfg with a comment above each setting. JSON
+// has no comments, so a JSON config is written without them.
+func writeCommentedConfig(cfg Config, format configFormat) ([]byte, error) {
+	raw := map[string]any{
+		"term":          string(cfg.Term),
+		"campus":        cfg.Campus,
+		"checkInterval": int64(cfg.CheckInterval),
+		"crns":          toAnySlice(cfg.CRNs.CRNs()),
+	}
+	if cfg.Email != "" {
+		raw["email"] = cfg.Email
+	}
+	if cfg.Ntfy != nil {
+		raw["ntfy"] = map[string]any{"topic": cfg.Ntfy.Topic}
+	}
+	if cfg.Telegram != nil {
+		raw["telegram"] = map[string]any{"token": cfg.Telegram.Token.Value(), "chatId": cfg.Telegram.ChatID, "commands": cfg.Telegram.Commands}
+	}
+	if cfg.Desktop != nil {
+		raw["desktop"] = map[string]any{"notify": cfg.Desktop.Notify, "bell": cfg.Desktop.Bell, "title": cfg.Desktop.Title}
+	}
+
+	if format == formatJSON {
+		return encodeRawConfig(raw, format)
+	}
+
+	var b strings.Builder
+	b.WriteString("# OpenSeat config written by `openseat init`. See the README for every option.\n")
+	for _, key := range configKeyOrder {
+		value, ok := raw[key]
+		if !ok {
+			continue
+		}
+		data, err := encodeRawConfig(map[string]any{key: value}, format)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "\n# %s\n%s", configComments[key], data)
+	}
+	return []byte(b.String()), nil
+}
+
+// toAnySlice converts strings to the []any a decoded config would hold
+func toAnySlice(values []string) []any {
+	items := make([]any, len(values))
+	for i, v := range values {
+		items[i] = v
+	}
+	return items
+}
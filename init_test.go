@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// openseat init tests
+// ===================
+
+// fakeTimetable serves the search form on GET and search results on POST
+func fakeTimetable(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`<form>
+				<select name="TERMYEAR"><option value="202609">Fall 2026</option><option value="202607">Summer II 2026</option></select>
+				<select name="CAMPUS"><option value="0">Blacksburg</option><option value="10">Virtual</option></select>
+			</form>`))
+			return
+		}
+		r.ParseForm()
+		rows := map[string]string{
+			"12345": `<tr><td>12345</td><td>CS-3114</td><td>Data Structures</td><td>Smith</td></tr>`,
+			"12346": `<tr><td>12346</td><td>CS-3114</td><td>Data Structures</td><td>Jones</td></tr>`,
+		}
+		body := `<table class="dataentrytable"><tr><th>CRN</th><th>Course</th><th>Title</th><th>Instructor</th></tr>`
+		switch {
+		case r.Form.Get("crn") != "":
+			body += rows[r.Form.Get("crn")]
+		case r.Form.Get("subj_code") == "CS" && r.Form.Get("CRSE_NUMBER") == "3114":
+			body += rows["12345"] + rows["12346"]
+		}
+		w.Write([]byte(body + `</table>`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestInitWizard_BuildsConfig(t *testing.T) {
+	server := fakeTimetable(t)
+	answers := strings.Join([]string{
+		"1",        // Fall 2026
+		"",         // Blacksburg
+		"99999",    // not in the timetable
+		"cs 3114",  // search
+		"2",        // pick 12346
+		"12345",    // by CRN
+		"12345",    // already added
+		"",         // done
+		"not-mail", // rejected
+		"me@example.com",
+		"", "", "", // no ntfy, telegram or desktop
+		"45",
+		"y", // send a test
+	}, "\n")
+	var out strings.Builder
+	sender := &MockEmailSender{}
+	w := &initWizard{in: bufio.NewScanner(strings.NewReader(answers)), out: &out, baseURL: server.URL, emailSender: sender}
+
+	cfg, err := w.run()
+	if err != nil {
+		t.Fatalf("run: %v\n%s", err, out.String())
+	}
+	if cfg.Term != "202609" || cfg.Campus != "0" || cfg.CheckInterval != 45 || cfg.Email != "me@example.com" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if got := cfg.CRNs.CRNs(); len(got) != 2 || got[0] != "12346" || got[1] != "12345" {
+		t.Errorf("CRNs = %v", got)
+	}
+	if cfg.BaseURL != "" {
+		t.Error("the timetable URL shouldn't be written to the config")
+	}
+	if len(sender.Sent) != 1 || sender.Sent[0].Subject != "OpenSeat test notification" {
+		t.Errorf("expected one test email, got %+v", sender.Sent)
+	}
+	for _, want := range []string{"1) Fall 2026 (202609)", "99999 wasn't found", "2) 12346 CS-3114 Data Structures - Jones", "12345 is already on the list", `"not-mail" is not a valid email address`, "email: sent"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestInitWizard_TermByHandWhenTimetableIsDown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var out strings.Builder
+	w := &initWizard{in: bufio.NewScanner(strings.NewReader("someday\nfall26\n")), out: &out, baseURL: server.URL}
+	term, err := w.choose("Term", nil, "next", func(s string) (string, error) {
+		term, err := ParseTerm(s, time.Now())
+		return string(term), err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term != "202609" || !strings.Contains(out.String(), `"someday" is not a term code`) {
+		t.Errorf("term = %q, output:\n%s", term, out.String())
+	}
+}
+
+func TestInitWizard_ChoiceValuesWinOverListNumbers(t *testing.T) {
+	campuses := []timetableOption{{Value: "0", Label: "Blacksburg"}, {Value: "2", Label: "Online"}, {Value: "10", Label: "Virtual"}}
+	parse := func(s string) (string, error) {
+		if !campusPattern.MatchString(s) {
+			return "", fmt.Errorf("%q is not a campus code", s)
+		}
+		return s, nil
+	}
+
+	for answer, want := range map[string]string{"2": "2", "3": "10", "10": "10", "1": "0", "": "0"} {
+		w := &initWizard{in: bufio.NewScanner(strings.NewReader(answer + "\n")), out: io.Discard}
+		got, err := w.choose("Campus", campuses, "0", parse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("answer %q picked campus %q, want %q", answer, got, want)
+		}
+	}
+}
+
+func TestWriteCommentedConfig_LoadsBack(t *testing.T) {
+	cfg := Config{
+		Term: "202609", Campus: "0", CheckInterval: 30, CRNs: newWatchList("12345", "67890"),
+		Email: "me@example.com", Ntfy: &NtfyConfig{Topic: "seats"}, Desktop: &DesktopConfig{Notify: true},
+	}
+	for _, name := range []string{"config.yaml", "config.toml", "config.json"} {
+		data, err := writeCommentedConfig(cfg, formatForPath(name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if commented := strings.Contains(string(data), "# CRNs to watch"); commented == (name == "config.json") {
+			t.Errorf("%s: comments present = %v\n%s", name, commented, data)
+		}
+
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+		if err != nil {
+			t.Fatalf("%s doesn't load: %v\n%s", name, err, data)
+		}
+		if len(loaded.CRNs) != 2 || loaded.Ntfy.Topic != "seats" || !loaded.Desktop.Notify {
+			t.Errorf("%s loaded as %+v", name, loaded)
+		}
+	}
+}
+
+func TestRunInitCommand_RefusesToOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("crns: []\n"), 0o600)
+
+	err := RunInitCommand([]string{path}, strings.NewReader(""), &strings.Builder{})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected an overwrite error, got %v", err)
+	}
+}
+
+func TestInitWizard_SavesBotTokenToDotenv(t *testing.T) {
+	dotenv := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(dotenv, []byte("RESEND_API_KEY=re_abc\nexport TELEGRAM_BOT_TOKEN=old\n"), 0o600)
+	w := &initWizard{out: &strings.Builder{}, dotenv: dotenv}
+	cfg := Config{Telegram: &TelegramConfig{Token: "123:it's-new", ChatID: "42"}}
+
+	if err := w.saveSecrets(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Telegram.Token != "env:TELEGRAM_BOT_TOKEN" {
+		t.Errorf("config token = %q, want a reference", cfg.Telegram.Token.Value())
+	}
+	data, _ := os.ReadFile(dotenv)
+	vars, err := parseDotenv(string(data))
+	if err != nil {
+		t.Fatalf("%v\n%s", err, data)
+	}
+	want := []dotenvVar{{"RESEND_API_KEY", "re_abc"}, {"TELEGRAM_BOT_TOKEN", "123:it's-new"}}
+	if !slices.Equal(vars, want) {
+		t.Errorf(".env holds %+v, want %+v", vars, want)
+	}
+}
@@ -3,20 +3,53 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
 	"os"
 )
 
 func main() {
-	// Check for --demo flag
-	for _, arg := range os.Args[1:] {
-		if arg == "--demo" {
-			RunDemo()
-			return
+	if len(os.Args) > 1 && os.Args[1] == "outbox" {
+		if err := RunOutboxCommand(os.Args[2:], os.LookupEnv, os.Stdout); err != nil {
+			log.Fatal(err)
 		}
+		return
 	}
 
-	if err := Run(RunOptions{ConfigPath: "config.json"}); err != nil {
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := RunConfigCommand(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		if err := RunInitCommand(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	opts, err := ParseArgs(os.Args[1:], os.LookupEnv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if opts.Demo {
+		RunDemo()
+		return
+	}
+
+	err = Run(RunOptions{
+		ConfigPath:     opts.ConfigPath,
+		ConfigOptional: opts.ConfigOptional,
+		Overrides:      opts.Overrides,
+	})
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MonitorControl lets interactive channels inspect and change a running monitor
+type MonitorControl interface {
+	Status() []CourseStatus
+	AddCRN(crn string) (CourseStatus, error)
+	RemoveCRN(crn string) bool
+	SetPaused(paused bool)
+	Paused() bool
+	Resolve(crn string, registered bool) error
+	Acknowledge(crn string) int
+}
+
+// acknowledger stops escalating alerts for a CRN ("" for every alert) and
+// returns how many were stopped
+type acknowledger interface {
+	Acknowledge(crn string) int
+}
+
+// commandListener is implemented by channels that accept remote commands
+type commandListener interface {
+	Listen(stop <-chan struct{}, ctl MonitorControl)
+}
+
+// Monitor holds the live watch list shared between the polling loop and
+// interactive channels. All methods are safe for concurrent use.
+type Monitor struct {
+	cfg       Config
+	now       func() time.Time
+	started   time.Time
+	escalator acknowledger // receives acknowledgements (optional)
+
+	mu         sync.Mutex
+	courses    []CourseStatus
+	paused     bool
+	failStreak int // consecutive failed checks across all courses
+}
+
+// CourseStats accumulates the check history of a course for digest reports
+type CourseStats struct {
+	Checks    int
+	Errors    int
+	Openings  int
+	OpenTime  time.Duration // time spent open, not counting the current opening
+	OpenSince time.Time     // start of the current opening, zero while closed
+	Seats     int           // open seats at the last successful check, -1 if unknown
+}
+
+// NewMonitor creates a monitor watching the given courses
+func NewMonitor(cfg Config, courses []CourseStatus) *Monitor {
+	for i := range courses {
+		courses[i].Stats.Seats = -1
+	}
+	return &Monitor{cfg: cfg, now: time.Now, started: time.Now(), courses: courses}
+}
+
+// Status returns a snapshot of every watched course
+func (m *Monitor) Status() []CourseStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]CourseStatus(nil), m.courses...)
+}
+
+// SetConfig switches to a reloaded config for course lookups and alert confirmation
+func (m *Monitor) SetConfig(cfg Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+}
+
+// AddCRN looks up the course name for crn and adds it to the watch list
+func (m *Monitor) AddCRN(crn string) (CourseStatus, error) {
+	if !crnPattern.MatchString(crn) {
+		return CourseStatus{}, fmt.Errorf("%q is not a CRN; CRNs are 5 digits, e.g. 12345", crn)
+	}
+
+	m.mu.Lock()
+	cfg := m.cfg
+	watched := m.watching(crn)
+	m.mu.Unlock()
+	if watched {
+		return CourseStatus{}, fmt.Errorf("CRN %s is already being monitored", crn)
+	}
+
+	// fetch outside the lock so the polling loop isn't blocked on the network
+	name, err := cfg.getCourseName(crn)
+	if err != nil {
+		return CourseStatus{}, err
+	}
+	name = cfg.watchList().Get(crn).displayName(name)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// another /add of the same CRN may have finished during the fetch
+	if m.watching(crn) {
+		return CourseStatus{}, fmt.Errorf("CRN %s is already being monitored", crn)
+	}
+	course := CourseStatus{CRN: crn, Name: name, Stats: CourseStats{Seats: -1}}
+	m.courses = append(m.courses, course)
+	return course, nil
+}
+
+// watching reports whether crn is on the watch list. Callers must hold m.mu.
+func (m *Monitor) watching(crn string) bool {
+	for _, c := range m.courses {
+		if c.CRN == crn {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveCRN stops watching crn. Returns false if it wasn't being watched.
+func (m *Monitor) RemoveCRN(crn string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, c := range m.courses {
+		if c.CRN == crn {
+			m.courses = append(m.courses[:i], m.courses[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SetPaused stops or resumes timetable polling
+func (m *Monitor) SetPaused(paused bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = paused
+}
+
+// Paused reports whether timetable polling is currently paused
+func (m *Monitor) Paused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
+// Resolve records the outcome of a registration attempt. If registered is true the
+// CRN is marked found and no longer checked ("I got it"); otherwise it is re-armed
+// so the next opening is reported again ("I missed it"). Either way its
+// escalating alerts stop.
+func (m *Monitor) Resolve(crn string, registered bool) error {
+	if err := m.resolve(crn, registered); err != nil {
+		return err
+	}
+	if m.escalator != nil {
+		m.escalator.Acknowledge(crn)
+	}
+	return nil
+}
+
+// resolve records a registration outcome without touching escalations
+func (m *Monitor) resolve(crn string, registered bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.courses {
+		if m.courses[i].CRN != crn {
+			continue
+		}
+		m.courses[i].Found = registered
+		m.courses[i].OpenStreak = 0
+		if m.courses[i].Open {
+			m.closeOpening(&m.courses[i])
+		}
+		return nil
+	}
+	return fmt.Errorf("CRN %s is not being monitored", crn)
+}
+
+// Acknowledge stops escalating alerts for crn ("" for every alert) and returns how many were stopped
+func (m *Monitor) Acknowledge(crn string) int {
+	if m.escalator == nil {
+		return 0
+	}
+	return m.escalator.Acknowledge(crn)
+}
+
+// rename changes the name shown for crn
+func (m *Monitor) rename(crn, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.courses {
+		if m.courses[i].CRN == crn {
+			m.courses[i].Name = name
+		}
+	}
+}
+
+// markFound records that crn has an open seat
+func (m *Monitor) markFound(crn string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.courses {
+		if m.courses[i].CRN == crn {
+			m.courses[i].Found = true
+		}
+	}
+}
+
+// transition describes how a check changed a course's open state
+type transition int
+
+const (
+	unchanged   transition = iota
+	openPending            // open, but not yet seen for enough consecutive checks
+	opened
+	closed
+)
+
+// observe records whether crn currently has open seats. An opening only counts
+// once it has been seen for the configured number of consecutive checks, so a
+// seat that flickers open for a single check doesn't trigger an alert.
+// Returns the resulting transition and the current run of open observations.
+func (m *Monitor) observe(crn string, open bool) (transition, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	confirm := max(m.cfg.Alerts.ConfirmChecks, 1)
+	for i := range m.courses {
+		c := &m.courses[i]
+		if c.CRN != crn {
+			continue
+		}
+
+		if !open {
+			c.OpenStreak = 0
+			c.Stats.Seats = 0
+			if c.Open {
+				m.closeOpening(c)
+				return closed, 0
+			}
+			return unchanged, 0
+		}
+
+		c.OpenStreak++
+		switch {
+		case c.Open:
+			return unchanged, c.OpenStreak
+		case c.OpenStreak < confirm:
+			return openPending, c.OpenStreak
+		default:
+			c.Open = true
+			c.Stats.Openings++
+			c.Stats.OpenSince = m.now()
+			return opened, c.OpenStreak
+		}
+	}
+	return unchanged, 0
+}
+
+// closeOpening marks an open course closed and adds the opening to its open
+// time. Callers must hold m.mu.
+func (m *Monitor) closeOpening(c *CourseStatus) {
+	c.Open = false
+	if !c.Stats.OpenSince.IsZero() {
+		c.Stats.OpenTime += m.now().Sub(c.Stats.OpenSince)
+	}
+	c.Stats.OpenSince = time.Time{}
+}
+
+// failureStreak returns how many checks in a row have failed, across all courses
+func (m *Monitor) failureStreak() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.failStreak
+}
+
+// recordSeats stores the open seat count reported by the latest check
+func (m *Monitor) recordSeats(crn string, seats int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.courses {
+		if m.courses[i].CRN == crn {
+			m.courses[i].Stats.Seats = seats
+		}
+	}
+}
+
+// counts returns how many watched courses have been found and the total watched
+func (m *Monitor) counts() (found, total int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.courses {
+		if c.Found {
+			found++
+		}
+	}
+	return found, len(m.courses)
+}
+
+// recordCheck updates the consecutive failure count for crn after a check.
+// Returns the new count, which is zero after a successful check.
+func (m *Monitor) recordCheck(crn string, err error) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.failStreak++
+	} else {
+		m.failStreak = 0
+	}
+	for i := range m.courses {
+		if m.courses[i].CRN != crn {
+			continue
+		}
+		m.courses[i].Stats.Checks++
+		if err != nil {
+			m.courses[i].Failures++
+			m.courses[i].Stats.Errors++
+		} else {
+			m.courses[i].Failures = 0
+		}
+		return m.courses[i].Failures
+	}
+	return 0
+}
+
+// consoleCommands accepts the same commands as chat channels, typed into the
+// terminal running the monitor (the leading slash is optional)
+type consoleCommands struct {
+	in io.Reader
+}
+
+func (c *consoleCommands) Listen(stop <-chan struct{}, ctl MonitorControl) {
+	scanner := bufio.NewScanner(c.in)
+	for scanner.Scan() {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "/") {
+			line = "/" + line
+		}
+		PrintCommandReply(handleCommand(line, ctl))
+	}
+}
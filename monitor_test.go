@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// Monitor tests
+// ===================
+
+func TestMonitor_ObserveReportsTransitions(t *testing.T) {
+	m := NewMonitor(Config{}, []CourseStatus{{CRN: "12345"}})
+
+	steps := []struct {
+		open bool
+		want transition
+	}{
+		{false, unchanged}, // still closed
+		{true, opened},     // opened
+		{true, unchanged},  // still open
+		{false, closed},    // closed again
+		{true, opened},     // reopened
+	}
+	for i, step := range steps {
+		if got, _ := m.observe("12345", step.open); got != step.want {
+			t.Errorf("step %d: observe(%v) = %v, want %v", i, step.open, got, step.want)
+		}
+	}
+}
+
+func TestMonitor_ObserveRequiresConfirmation(t *testing.T) {
+	m := NewMonitor(Config{Alerts: AlertPolicy{ConfirmChecks: 3}}, []CourseStatus{{CRN: "12345"}})
+
+	steps := []struct {
+		open bool
+		want transition
+	}{
+		{true, openPending},
+		{true, openPending},
+		{false, unchanged}, // flicker resets the streak without alerting
+		{true, openPending},
+		{true, openPending},
+		{true, opened},
+	}
+	for i, step := range steps {
+		if got, _ := m.observe("12345", step.open); got != step.want {
+			t.Errorf("step %d: observe(%v) = %v, want %v", i, step.open, got, step.want)
+		}
+	}
+}
+
+func TestMonitor_ResolveGotIt(t *testing.T) {
+	m := NewMonitor(Config{}, []CourseStatus{{CRN: "12345", Open: true}})
+
+	if err := m.Resolve("12345", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c := m.Status()[0]; !c.Found || c.Open {
+		t.Errorf("expected found and not open, got %+v", c)
+	}
+}
+
+func TestMonitor_ResolveMissedItRearms(t *testing.T) {
+	m := NewMonitor(Config{}, []CourseStatus{{CRN: "12345", Found: true, Open: true}})
+
+	if err := m.Resolve("12345", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c := m.Status()[0]; c.Found || c.Open {
+		t.Errorf("expected re-armed course, got %+v", c)
+	}
+	// the next open observation should alert again
+	if got, _ := m.observe("12345", true); got != opened {
+		t.Error("expected re-armed course to report the next opening")
+	}
+}
+
+func TestMonitor_ResolveClosesOpenInterval(t *testing.T) {
+	now := time.Date(2026, 1, 12, 9, 0, 0, 0, time.UTC)
+	m := NewMonitor(Config{}, []CourseStatus{{CRN: "12345"}})
+	m.now = func() time.Time { return now }
+
+	m.observe("12345", true)
+	now = now.Add(10 * time.Minute)
+	if err := m.Resolve("12345", false); err != nil {
+		t.Fatal(err)
+	}
+	stats := m.Status()[0].Stats
+	if stats.OpenTime != 10*time.Minute || !stats.OpenSince.IsZero() {
+		t.Errorf("open time = %s, open since = %s; want 10m and closed", stats.OpenTime, stats.OpenSince)
+	}
+}
+
+func TestMonitor_ResolveUnknownCRN(t *testing.T) {
+	m := NewMonitor(Config{}, nil)
+	if err := m.Resolve("99999", true); err == nil {
+		t.Error("expected error for unknown CRN")
+	}
+}
+
+func TestMonitor_RecordCheckCountsFailures(t *testing.T) {
+	m := NewMonitor(Config{}, []CourseStatus{{CRN: "12345"}})
+
+	m.recordCheck("12345", errors.New("timeout"))
+	if got := m.recordCheck("12345", errors.New("timeout")); got != 2 {
+		t.Errorf("failures = %d, want 2", got)
+	}
+	if got := m.recordCheck("12345", nil); got != 0 {
+		t.Errorf("failures after success = %d, want 0", got)
+	}
+}
+
+func TestMonitor_TracksOpenTimeAndSeats(t *testing.T) {
+	m := NewMonitor(Config{}, []CourseStatus{{CRN: "12345"}})
+	now := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	m.recordCheck("12345", nil)
+	m.observe("12345", true)
+	m.recordSeats("12345", 4)
+	now = now.Add(7 * time.Minute)
+	m.recordCheck("12345", nil)
+	m.observe("12345", false)
+
+	stats := m.Status()[0].Stats
+	if stats.Checks != 2 || stats.Openings != 1 {
+		t.Errorf("checks/openings = %d/%d, want 2/1", stats.Checks, stats.Openings)
+	}
+	if stats.OpenTime != 7*time.Minute || !stats.OpenSince.IsZero() {
+		t.Errorf("open time = %v since %v, want 7m and closed", stats.OpenTime, stats.OpenSince)
+	}
+	if stats.Seats != 0 {
+		t.Errorf("seats after closing = %d, want 0", stats.Seats)
+	}
+}
+
+// ===================
+// consoleCommands tests
+// ===================
+
+func TestConsoleCommands_AcceptsCommandsWithoutSlash(t *testing.T) {
+	m := NewMonitor(Config{}, []CourseStatus{{CRN: "12345", Open: true}})
+	c := &consoleCommands{in: strings.NewReader("got 12345\n")}
+
+	c.Listen(make(chan struct{}), m)
+
+	if !m.Status()[0].Found {
+		t.Error("expected 'got 12345' to mark the course found")
+	}
+}
@@ -0,0 +1,919 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RegistrationURL is the VT registration page opened by notification click actions
+const RegistrationURL = "https://registration.banner.vt.edu/StudentRegistrationSsb/ssb/registration"
+
+// notifyClient is shared by the HTTP-based notification channels
+var notifyClient = &http.Client{Timeout: 15 * time.Second}
+
+// ===================================
+// Events
+// ===================================
+
+// EventKind identifies what happened to a monitored section
+type EventKind int
+
+const (
+	EventSeatOpen   EventKind = iota // a section has at least one open seat
+	EventSeatClosed                  // a previously open section filled up again
+	EventError                       // checking a section failed
+	EventDigest                      // periodic status report
+	EventUnhealthy                   // every recent check has failed
+	EventShutdown                    // the monitor is exiting
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventSeatOpen:
+		return "seat_open"
+	case EventSeatClosed:
+		return "seat_closed"
+	case EventError:
+		return "error"
+	case EventDigest:
+		return "digest"
+	case EventUnhealthy:
+		return "unhealthy"
+	case EventShutdown:
+		return "shutdown"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the kind by name so hook scripts see "seat_open" rather than 0
+func (k EventKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a kind written by MarshalText
+func (k *EventKind) UnmarshalText(text []byte) error {
+	for kind := EventSeatOpen; kind <= EventShutdown; kind++ {
+		if kind.String() == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown event kind %q", text)
+}
+
+// durable reports whether undelivered events of this kind are worth retrying
+// later. Routine errors and closings are stale by the time a retry would succeed.
+func (k EventKind) durable() bool {
+	return k != EventError && k != EventSeatClosed
+}
+
+// Event describes something worth telling the user about
+type Event struct {
+	Kind     EventKind `json:"event"`
+	CRN      string    `json:"crn"`
+	Name     string    `json:"name"`
+	Message  string    `json:"message,omitempty"`
+	Time     time.Time `json:"time"`
+	Section  *Section  `json:"section,omitempty"`  // timetable details, when known
+	Failures int       `json:"failures,omitempty"` // consecutive check failures for error events
+	Batch    []Event   `json:"batch,omitempty"`    // openings summarized by a per-cycle batch event
+	AckURL   string    `json:"ackUrl,omitempty"`   // link that stops escalation of this opening
+
+	// recipients an earlier partial send missed; a retry only goes to them
+	Recipients []string `json:"recipients,omitempty"`
+
+	// content rendered from user templates for one channel, overriding the built-in text
+	title, text, html string
+}
+
+// label names the course for headlines, preferring the course code when known
+func (e Event) label() string {
+	if e.Section != nil && e.Section.Course != "" {
+		return e.Section.Course + " " + e.Name
+	}
+	if e.Name != "" {
+		return e.Name
+	}
+	return e.CRN
+}
+
+// CRNs lists every CRN the event covers
+func (e Event) CRNs() []string {
+	if len(e.Batch) == 0 {
+		return []string{e.CRN}
+	}
+	crns := make([]string, len(e.Batch))
+	for i, b := range e.Batch {
+		crns[i] = b.CRN
+	}
+	return crns
+}
+
+// covers returns the events a message stands for: the openings of a batch, or the event itself
+func (e Event) covers() []Event {
+	if len(e.Batch) > 0 {
+		return e.Batch
+	}
+	return []Event{e}
+}
+
+// detail formats an opening as a single line with its seats and meeting time when known
+func (e Event) detail() string {
+	line := fmt.Sprintf("%s (CRN %s)", e.label(), e.CRN)
+	if s := e.Section; s != nil {
+		if s.Seats >= 0 {
+			line += fmt.Sprintf(" - %d seats", s.Seats)
+		}
+		if meeting := s.MeetingTime(); meeting != "" {
+			line += " - " + meeting
+		}
+	}
+	return line
+}
+
+// batchEvent summarizes several openings from the same cycle as one event
+func batchEvent(openings []Event) Event {
+	return Event{Kind: EventSeatOpen, Time: time.Now(), Batch: openings}
+}
+
+// Title returns a short human-readable headline for the event
+func (e Event) Title() string {
+	if e.title != "" {
+		return e.title
+	}
+	switch e.Kind {
+	case EventSeatOpen:
+		if len(e.Batch) > 0 {
+			labels := make([]string, len(e.Batch))
+			for i, b := range e.Batch {
+				labels[i] = b.label()
+			}
+			return "Seats open: " + strings.Join(labels, ", ")
+		}
+		return "Seat open: " + e.label()
+	case EventSeatClosed:
+		return "Seat closed: " + e.label()
+	case EventError:
+		return fmt.Sprintf("OpenSeat error checking %s", e.CRN)
+	case EventDigest:
+		return "OpenSeat status digest"
+	case EventUnhealthy:
+		return "OpenSeat checks are failing"
+	case EventShutdown:
+		return "OpenSeat stopped"
+	default:
+		return "OpenSeat"
+	}
+}
+
+// Body returns the plain-text notification body for the event
+func (e Event) Body() string {
+	if e.text != "" {
+		return e.text
+	}
+	if e.Message != "" {
+		return e.Message
+	}
+	switch {
+	case len(e.Batch) > 0:
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d sections opened:\n", len(e.Batch))
+		for _, opening := range e.Batch {
+			fmt.Fprintf(&b, "- %s\n", opening.detail())
+			if opening.AckURL != "" {
+				fmt.Fprintf(&b, "  Acknowledge: %s\n", opening.AckURL)
+			}
+		}
+		fmt.Fprintf(&b, "\nRegister: %s", RegistrationURL)
+		return b.String()
+	case e.Kind == EventSeatOpen && e.AckURL != "":
+		return fmt.Sprintf("OPEN SEAT: %s (CRN: %s)\nAcknowledge: %s", e.Name, e.CRN, e.AckURL)
+	case e.Kind == EventSeatOpen:
+		return fmt.Sprintf("OPEN SEAT: %s (CRN: %s)", e.Name, e.CRN)
+	case e.Kind == EventSeatClosed:
+		return fmt.Sprintf("CLOSED: %s (CRN: %s)", e.Name, e.CRN)
+	default:
+		return e.CRN
+	}
+}
+
+// HTML returns the rendered HTML body, or "" when none was rendered
+func (e Event) HTML() string {
+	return e.html
+}
+
+// ===================================
+// Notifier abstraction
+// ===================================
+
+// Notifier delivers events to a single notification channel
+type Notifier interface {
+	Name() string // channel name shown in the UI (e.g. "email", "ntfy")
+	Notify(ev Event) error
+}
+
+// partialSendError reports a message that reached some of a channel's
+// recipients but not the others
+type partialSendError struct {
+	Failed []string // recipients that didn't get the message
+	err    error
+}
+
+func (e *partialSendError) Error() string {
+	return fmt.Sprintf("not sent to %s: %v", strings.Join(e.Failed, ", "), e.err)
+}
+
+func (e *partialSendError) Unwrap() error { return e.err }
+
+// multiRecipientNotifier is implemented by channels that send a separate
+// provider message to each recipient, so quotas count every one of them
+type multiRecipientNotifier interface {
+	recipients(ev Event) []string
+}
+
+// messageCount returns how many provider messages a channel sends for ev
+func messageCount(n Notifier, ev Event) int {
+	if m, ok := n.(multiRecipientNotifier); ok {
+		return len(m.recipients(ev))
+	}
+	return 1
+}
+
+// eventFilter is implemented by notifiers that only want some events
+type eventFilter interface {
+	Accepts(ev Event) bool
+}
+
+// defaultErrorThreshold is how many consecutive failed checks of a CRN it
+// takes before a channel hears about the error
+const defaultErrorThreshold = 3
+
+// reachesErrorThreshold reports whether a check error is the one that brings its
+// CRN's failure streak to threshold (0 = default), so a channel hears about an
+// outage once rather than every cycle
+func reachesErrorThreshold(ev Event, threshold int) bool {
+	if threshold == 0 {
+		threshold = defaultErrorThreshold
+	}
+	return ev.Failures == threshold
+}
+
+// statusObserver is implemented by notifiers that display live monitor progress
+type statusObserver interface {
+	UpdateStatus(found, total int)
+}
+
+// EmailNotifier adapts an EmailSender to the Notifier interface.
+// Seat openings, digests and monitor health alerts are emailed; routine check
+// errors and closings are not.
+type EmailNotifier struct {
+	Sender EmailSender
+	To     string
+}
+
+func (n *EmailNotifier) Name() string { return "email" }
+
+func (n *EmailNotifier) Notify(ev Event) error {
+	switch ev.Kind {
+	case EventError, EventSeatClosed:
+		return nil
+	}
+	if h, ok := n.Sender.(htmlEmailSender); ok && ev.HTML() != "" {
+		return h.SendHTML(n.To, ev.Title(), ev.Body(), ev.HTML())
+	}
+	return n.Sender.Send(n.To, ev.Title(), ev.Body())
+}
+
+// buildNotifiers creates one Notifier per channel enabled in the config
+func buildNotifiers(cfg Config, emailSender EmailSender) []Notifier {
+	var notifiers []Notifier
+	if cfg.Email != "" {
+		notifiers = append(notifiers, &EmailNotifier{Sender: emailSender, To: cfg.Email})
+	}
+	if cfg.Ntfy != nil {
+		notifiers = append(notifiers, &NtfyNotifier{Config: *cfg.Ntfy})
+	}
+	if cfg.Gotify != nil {
+		notifiers = append(notifiers, &GotifyNotifier{Config: *cfg.Gotify})
+	}
+	if cfg.Telegram != nil {
+		notifiers = append(notifiers, &TelegramNotifier{Config: *cfg.Telegram})
+	}
+	if cfg.SMS != nil {
+		notifiers = append(notifiers, &SMSNotifier{Config: *cfg.SMS})
+	}
+	if cfg.Desktop != nil {
+		notifiers = append(notifiers, &DesktopNotifier{Config: *cfg.Desktop})
+	}
+	if cfg.Exec != nil {
+		notifiers = append(notifiers, newExecNotifier(cfg))
+	}
+	return notifiers
+}
+
+// ===================================
+// Dispatch
+// ===================================
+
+// AlertPolicy controls how eagerly openings turn into notifications, so sections
+// that flicker open and closed as students drop and add don't cause alert spam
+type AlertPolicy struct {
+	ConfirmChecks    int            `json:"confirmChecks"`    // Consecutive open checks required before alerting (default 1)
+	Cooldown         int            `json:"cooldown"`         // Seconds before a channel repeats an alert for the same CRN and event (default 0)
+	ChannelCooldowns map[string]int `json:"channelCooldowns"` // Per-channel cooldown overrides in seconds, keyed by channel name
+	UrgentChannels   []string       `json:"urgentChannels"`   // Channels alerted per CRN immediately instead of once per cycle
+}
+
+// cooldown returns the repeat-suppression window for a channel
+func (p AlertPolicy) cooldown(channel string) time.Duration {
+	if secs, ok := p.ChannelCooldowns[channel]; ok {
+		return time.Duration(secs) * time.Second
+	}
+	return time.Duration(p.Cooldown) * time.Second
+}
+
+// urgentNotifier is implemented by channels that prefer an immediate alert per
+// CRN over the per-cycle summary (e.g. scripts that expect a single OPENSEAT_CRN)
+type urgentNotifier interface {
+	PrefersUrgent() bool
+}
+
+// urgent reports whether a channel receives each opening immediately
+func (p AlertPolicy) urgent(n Notifier) bool {
+	if slices.Contains(p.UrgentChannels, n.Name()) {
+		return true
+	}
+	u, ok := n.(urgentNotifier)
+	return ok && u.PrefersUrgent()
+}
+
+// backgroundNotifier is implemented by channels slow enough to hold up the
+// poll loop, like user scripts, which are delivered to on their own goroutine
+type backgroundNotifier interface {
+	RunsInBackground() bool
+}
+
+// DedupeKey identifies repeats of the same event for cooldown purposes
+func (e Event) DedupeKey() string {
+	return e.Kind.String() + ":" + e.CRN
+}
+
+// Dispatcher fans events out to notifiers, suppressing repeats that fall
+// inside a channel's cooldown window. Seat openings are held until Flush so
+// that everything that opened in one cycle goes out as a single message.
+type Dispatcher struct {
+	settings  atomic.Pointer[dispatchSettings] // replaced as a whole on reload
+	outbox    *Outbox                          // persists deliveries for retry (optional)
+	escalator *Escalator                       // escalation chain for unacknowledged openings (optional)
+	courses   func() []CourseStatus            // watch list snapshot for templates (optional)
+	quotas    *QuotaTracker                    // per-channel send limits (optional)
+	profile   string                           // profile whose quota counters this dispatcher uses
+	now       func() time.Time
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time // keyed by channel + "|" + dedupe key
+	pending  []Event              // openings waiting for the end of the cycle
+	busy     map[string]int       // background deliveries in progress, keyed by channel
+
+	background sync.WaitGroup // background deliveries, waited for on shutdown
+}
+
+// dispatchSettings is the part of a Dispatcher that a config reload changes.
+// A published value is never modified, so deliveries running in the
+// background and command listeners can read it while a reload swaps it.
+type dispatchSettings struct {
+	notifiers []Notifier
+	policy    AlertPolicy
+	quiet     *QuietHoursConfig   // per-channel quiet hours (optional)
+	templates *Templates          // user overrides for message content (optional)
+	channels  map[string][]string // channels each CRN is limited to, keyed by CRN (optional)
+}
+
+// NewDispatcher creates a dispatcher for the given notifiers and alert policy
+func NewDispatcher(notifiers []Notifier, policy AlertPolicy) *Dispatcher {
+	d := &Dispatcher{
+		now:      time.Now,
+		lastSent: map[string]time.Time{},
+		busy:     map[string]int{},
+	}
+	d.settings.Store(&dispatchSettings{notifiers: notifiers, policy: policy})
+	return d
+}
+
+// current returns the settings in effect
+func (d *Dispatcher) current() *dispatchSettings {
+	return d.settings.Load()
+}
+
+// configure publishes a copy of the settings changed by update
+func (d *Dispatcher) configure(update func(s *dispatchSettings)) {
+	for {
+		old := d.settings.Load()
+		next := *old
+		update(&next)
+		if d.settings.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// Dispatch sends the event to every notifier and reports the outcome of each in the UI.
+// Seat openings only go to urgent channels here; the rest receive them from Flush.
+// Channels in an escalation chain receive escalating openings from Escalate instead.
+func (d *Dispatcher) Dispatch(ev Event) {
+	settings := d.current()
+	escalating := d.escalator != nil && d.escalator.handles(ev)
+	if escalating {
+		ev = d.escalator.Start(ev)
+	}
+	if ev.Kind == EventSeatClosed && d.escalator != nil {
+		d.escalator.Acknowledge(ev.CRN) // nothing left to register for
+	}
+
+	if ev.Kind == EventSeatOpen {
+		d.mu.Lock()
+		d.pending = append(d.pending, ev)
+		d.mu.Unlock()
+	}
+
+	for _, n := range settings.notifiers {
+		if ev.Kind == EventSeatOpen && !settings.policy.urgent(n) {
+			continue
+		}
+		if escalating && d.escalator.manages(n.Name(), ev) {
+			continue
+		}
+		if d.allowed(n, ev) && len(d.route(n, []Event{ev})) > 0 {
+			d.deliver(n, ev, []Event{ev}, nil)
+		}
+	}
+
+	if escalating {
+		d.Escalate()
+	}
+}
+
+// Escalate sends escalation steps that have come due for unacknowledged openings
+func (d *Dispatcher) Escalate() {
+	settings := d.current()
+	if d.escalator == nil {
+		return
+	}
+
+	for _, step := range d.escalator.Due() {
+		idx := slices.IndexFunc(settings.notifiers, func(n Notifier) bool { return n.Name() == step.Channel })
+		if idx < 0 {
+			continue // channel not configured
+		}
+		n := settings.notifiers[idx]
+		if d.allowed(n, step.Event) && len(d.route(n, []Event{step.Event})) > 0 {
+			PrintEscalated(step.Event.CRN, n.Name())
+			d.deliver(n, step.Event, []Event{step.Event}, nil)
+		}
+	}
+}
+
+// Escalating reports whether any opening is still waiting for acknowledgement
+func (d *Dispatcher) Escalating() bool {
+	return d.escalator != nil && d.escalator.Pending()
+}
+
+// Flush sends the openings collected since the last flush to every non-urgent
+// channel, as one summary message when more than one section opened
+func (d *Dispatcher) Flush() {
+	settings := d.current()
+	d.mu.Lock()
+	openings := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+	if len(openings) == 0 {
+		return
+	}
+
+	for _, n := range settings.notifiers {
+		if settings.policy.urgent(n) {
+			continue
+		}
+
+		var accepted []Event
+		for _, ev := range openings {
+			if d.escalator != nil && d.escalator.manages(n.Name(), ev) {
+				continue
+			}
+			if d.allowed(n, ev) {
+				accepted = append(accepted, ev)
+			}
+		}
+		d.deliverOpenings(n, d.route(n, accepted))
+	}
+}
+
+// deliverOpenings sends openings to a channel, summarized when there are several
+func (d *Dispatcher) deliverOpenings(n Notifier, openings []Event) {
+	switch len(openings) {
+	case 0:
+	case 1:
+		d.deliver(n, openings[0], openings, nil)
+	default:
+		d.deliver(n, batchEvent(openings), openings, nil)
+	}
+}
+
+// route applies the channel's quiet hours to events that passed its filters and
+// returns the ones to deliver now. Openings are held or rerouted as configured.
+func (d *Dispatcher) route(n Notifier, events []Event) []Event {
+	settings := d.current()
+	if settings.quiet == nil {
+		return events
+	}
+
+	now := d.now()
+	var deliverNow []Event
+	for _, ev := range events {
+		switch settings.quiet.decide(n.Name(), ev, now) {
+		case quietSend:
+			deliverNow = append(deliverNow, ev)
+		case quietHold:
+			d.hold(n.Name(), ev)
+		case quietDowngrade:
+			d.downgrade(n, ev)
+		}
+	}
+	return deliverNow
+}
+
+// hold parks an opening in the outbox until the channel's quiet hours end, so
+// it survives a restart and RetryDue sends it with the rest held that night
+func (d *Dispatcher) hold(channel string, ev Event) {
+	settings := d.current()
+	if d.outbox == nil {
+		PrintSuppressed(ev.CRN, channel+" quiet hours")
+		return
+	}
+	if _, err := d.outbox.Hold(channel, ev, settings.quiet.ends(channel, d.now())); err != nil {
+		PrintOutboxError(err)
+		return
+	}
+	PrintSuppressed(ev.CRN, fmt.Sprintf("%s quiet hours, holding until %s", channel, settings.quiet.Channels[channel].End))
+}
+
+// downgrade sends an opening through the channel's fallback instead. If the
+// fallback isn't configured or is quiet too, the opening is held instead.
+func (d *Dispatcher) downgrade(n Notifier, ev Event) {
+	settings := d.current()
+	fallback := settings.quiet.Channels[n.Name()].Fallback
+	idx := slices.IndexFunc(settings.notifiers, func(f Notifier) bool { return f.Name() == fallback })
+	if idx < 0 || settings.quiet.active(fallback, d.now()) {
+		d.hold(n.Name(), ev)
+		return
+	}
+
+	PrintSuppressed(ev.CRN, fmt.Sprintf("%s quiet hours, sending via %s instead", n.Name(), fallback))
+	if fb := settings.notifiers[idx]; !d.reaches(fb, ev) {
+		d.deliver(fb, ev, []Event{ev}, nil)
+	}
+	// otherwise the fallback already receives this opening on its own
+}
+
+// allowed applies the channel's routing, event filter and cooldown to a single event
+func (d *Dispatcher) allowed(n Notifier, ev Event) bool {
+	if !d.routed(n, ev) {
+		return false
+	}
+	if remaining := d.cooldownRemaining(n.Name(), ev); remaining > 0 {
+		PrintSuppressed(ev.CRN, fmt.Sprintf("%s %s in cooldown for %s", n.Name(), ev.Kind, remaining.Round(time.Second)))
+		return false
+	}
+	return true
+}
+
+// routed reports whether the event is meant for the channel: the CRN's
+// channels include it and the channel's event filter accepts it
+func (d *Dispatcher) routed(n Notifier, ev Event) bool {
+	if only := d.current().channels[ev.CRN]; len(only) > 0 && !slices.Contains(only, n.Name()) {
+		return false
+	}
+	f, ok := n.(eventFilter)
+	return !ok || f.Accepts(ev)
+}
+
+// deliver sends msg to a notifier, on its own goroutine for background channels
+func (d *Dispatcher) deliver(n Notifier, msg Event, covers []Event, retry *OutboxEntry) {
+	if b, ok := n.(backgroundNotifier); !ok || !b.RunsInBackground() {
+		d.deliverNow(n, msg, covers, retry)
+		return
+	}
+
+	d.mu.Lock()
+	d.busy[n.Name()]++
+	d.mu.Unlock()
+	d.background.Add(1)
+	go func() {
+		defer d.background.Done()
+		d.deliverNow(n, msg, covers, retry)
+		d.mu.Lock()
+		d.busy[n.Name()]--
+		d.mu.Unlock()
+	}()
+}
+
+// Wait blocks until background deliveries have finished
+func (d *Dispatcher) Wait() {
+	d.background.Wait()
+}
+
+// deliverNow sends msg to a notifier and starts the cooldown for each event it covers.
+// retry is the outbox entry being redelivered, or nil for a first send.
+// A channel that is over quota or rate-limited hands the message to its fallback
+// channel, which only repeats the events it doesn't already receive on its own.
+func (d *Dispatcher) deliverNow(n Notifier, msg Event, covers []Event, retry *OutboxEntry) {
+	tried := map[string]bool{}
+	for {
+		tried[n.Name()] = true
+		reason := d.send(n, msg, covers, retry)
+		if reason == "" {
+			return
+		}
+
+		fallback := d.quotaFallback(n.Name())
+		if fallback == nil || tried[fallback.Name()] {
+			PrintQuotaFallback(n.Name(), reason, "")
+			return
+		}
+		PrintQuotaFallback(n.Name(), reason, fallback.Name())
+
+		var missed []Event
+		for _, ev := range covers {
+			if !d.reaches(fallback, ev) {
+				missed = append(missed, ev)
+			}
+		}
+		switch {
+		case len(missed) == 0:
+			return
+		case len(missed) == len(covers):
+		case len(missed) == 1:
+			msg = missed[0]
+		default:
+			msg = batchEvent(missed)
+		}
+		n, covers, retry = fallback, missed, nil
+	}
+}
+
+// reaches reports whether a channel gets the event through its own routing:
+// it already sent it, or the event is routed to it, passes its filter, isn't
+// left to the escalation chain and isn't held back by its cooldown
+func (d *Dispatcher) reaches(n Notifier, ev Event) bool {
+	if !d.routed(n, ev) {
+		return false
+	}
+
+	d.mu.Lock()
+	last, sent := d.lastSent[n.Name()+"|"+ev.DedupeKey()]
+	d.mu.Unlock()
+	if sent && !last.Before(ev.Time) {
+		return true
+	}
+	if d.escalator != nil && d.escalator.manages(n.Name(), ev) {
+		return false
+	}
+	return d.cooldownRemaining(n.Name(), ev) <= 0
+}
+
+// send delivers msg through one notifier. Durable events are written to the
+// outbox first so a failed delivery is retried; a retry reuses its existing entry.
+// It returns why the channel's quota or provider rate limit stopped the message,
+// or "" otherwise.
+func (d *Dispatcher) send(n Notifier, msg Event, covers []Event, retry *OutboxEntry) (limited string) {
+	if d.quotas != nil {
+		if ok, reason := d.quotas.Allow(d.profile, n.Name(), d.now()); !ok {
+			if retry != nil {
+				// handed to the fallback if there is one, otherwise retried after a backoff
+				d.settleOutbox(retry.ID, errors.New(reason), d.quotaFallback(n.Name()) != nil)
+			}
+			return reason
+		}
+	}
+
+	var id string
+	switch {
+	case retry != nil:
+		id = retry.ID
+	case d.outbox != nil && msg.Kind.durable():
+		var err error
+		if id, err = d.outbox.Add(n.Name(), msg); err != nil {
+			PrintOutboxError(err)
+		}
+	}
+
+	err := n.Notify(d.render(n, msg))
+	var partial *partialSendError
+	if err != nil {
+		if retry != nil {
+			PrintRetryFailed(n.Name(), retry.Attempts+1, err)
+		} else {
+			PrintNotifyError(n.Name(), err)
+		}
+		if d.quotas != nil && errors.Is(err, errRateLimited) {
+			if err := d.quotas.RateLimited(d.profile, n.Name(), d.now()); err != nil {
+				PrintNotifyError(n.Name(), err)
+			}
+			limited = "provider rate limit"
+		}
+	}
+	if errors.As(err, &partial) {
+		// the message went out, so it isn't handed to a fallback that would
+		// repeat it to everyone; only the recipients that missed it are retried
+		if id != "" {
+			d.settleOutbox(id, nil, true)
+		}
+		if d.outbox != nil && msg.Kind.durable() {
+			d.retryRecipients(n.Name(), msg, partial)
+		}
+		err, limited = nil, ""
+	}
+	if err != nil {
+		if id != "" {
+			d.settleOutbox(id, err, limited != "" && d.quotaFallback(n.Name()) != nil)
+		}
+		return limited
+	}
+	if id != "" {
+		d.settleOutbox(id, nil, true)
+	}
+	if d.quotas != nil {
+		sent := messageCount(n, msg)
+		if partial != nil {
+			sent -= len(partial.Failed)
+		}
+		if err := d.quotas.Record(d.profile, n.Name(), sent, d.now()); err != nil {
+			PrintNotifyError(n.Name(), err)
+		}
+	}
+	for _, ev := range covers {
+		d.markSent(n.Name(), ev)
+	}
+
+	if partial != nil {
+		return ""
+	}
+	if retry != nil {
+		PrintRetrySent(n.Name(), msg.Title())
+		return ""
+	}
+	if msg.Kind != EventSeatOpen {
+		return ""
+	}
+	if email, ok := n.(*EmailNotifier); ok {
+		PrintEmailSent(email.To)
+	} else {
+		PrintNotificationSent(n.Name())
+	}
+	return ""
+}
+
+// retryRecipients queues msg for the recipients a partial send missed
+func (d *Dispatcher) retryRecipients(channel string, msg Event, partial *partialSendError) {
+	msg.Recipients = partial.Failed
+	id, err := d.outbox.Add(channel, msg)
+	if err == nil {
+		err = d.outbox.Fail(id, partial)
+	}
+	if err != nil {
+		PrintOutboxError(err)
+	}
+}
+
+// settleOutbox removes a delivered (or handed-off) entry, or schedules a retry
+func (d *Dispatcher) settleOutbox(id string, deliveryErr error, done bool) {
+	var err error
+	if done {
+		err = d.outbox.Done(id)
+	} else {
+		err = d.outbox.Fail(id, deliveryErr)
+	}
+	if err != nil {
+		PrintOutboxError(err)
+	}
+}
+
+// quotaFallback returns the configured notifier to use when a channel is over quota, or nil
+func (d *Dispatcher) quotaFallback(channel string) Notifier {
+	settings := d.current()
+	if d.quotas == nil {
+		return nil
+	}
+	name := d.quotas.Config.Channels[channel].Fallback
+	if name == "" {
+		return nil
+	}
+	idx := slices.IndexFunc(settings.notifiers, func(n Notifier) bool { return n.Name() == name })
+	if idx < 0 {
+		return nil
+	}
+	return settings.notifiers[idx]
+}
+
+// render applies the channel's templates to an event. A template that fails
+// to render is reported and the built-in content is sent instead.
+func (d *Dispatcher) render(n Notifier, ev Event) Event {
+	settings := d.current()
+	if settings.templates == nil {
+		return ev
+	}
+	var courses []CourseStatus
+	if d.courses != nil {
+		courses = d.courses()
+	}
+	rendered, err := settings.templates.Apply(n.Name(), ev, courses)
+	if err != nil {
+		PrintNotifyError(n.Name(), err)
+		return ev
+	}
+	return rendered
+}
+
+// RetryDue redelivers outbox entries whose backoff has expired, including
+// anything left pending by a previous run. Retries go through the same channel
+// routing, quiet hours and quotas as first sends, but not cooldowns: the events
+// passed those when first sent, and a partial send already started them.
+func (d *Dispatcher) RetryDue() {
+	settings := d.current()
+	if d.outbox == nil {
+		return
+	}
+
+	for _, entry := range d.outbox.Due() {
+		idx := slices.IndexFunc(settings.notifiers, func(n Notifier) bool { return n.Name() == entry.Channel })
+		if idx < 0 {
+			continue // channel no longer configured; leave it for `openseat outbox`
+		}
+		n := settings.notifiers[idx]
+		d.mu.Lock()
+		busy := d.busy[n.Name()] > 0
+		d.mu.Unlock()
+		if busy {
+			continue // the entry may still be in flight; check again next cycle
+		}
+
+		var accepted []Event
+		for _, ev := range entry.Event.covers() {
+			if d.routed(n, ev) {
+				accepted = append(accepted, ev)
+			}
+		}
+		covers := d.route(n, accepted)
+
+		switch {
+		case len(covers) == 0:
+			// filtered out, held for quiet hours or downgraded to another channel
+			d.settleOutbox(entry.ID, nil, true)
+		case len(covers) == len(entry.Event.covers()):
+			d.deliver(n, entry.Event, covers, &entry)
+		case len(covers) == 1:
+			d.deliver(n, covers[0], covers, &entry)
+		default:
+			d.deliver(n, batchEvent(covers), covers, &entry)
+		}
+	}
+}
+
+// cooldownRemaining returns how much longer a channel must wait before repeating this event
+func (d *Dispatcher) cooldownRemaining(channel string, ev Event) time.Duration {
+	window := d.current().policy.cooldown(channel)
+	if window <= 0 {
+		return 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last, ok := d.lastSent[channel+"|"+ev.DedupeKey()]
+	if !ok {
+		return 0
+	}
+	return window - d.now().Sub(last)
+}
+
+// markSent starts the cooldown window for this channel and event
+func (d *Dispatcher) markSent(channel string, ev Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastSent[channel+"|"+ev.DedupeKey()] = d.now()
+}
+
+// ReportStatus passes the current found/total counts to notifiers that display progress
+func (d *Dispatcher) ReportStatus(found, total int) {
+	for _, n := range d.current().notifiers {
+		if o, ok := n.(statusObserver); ok {
+			o.UpdateStatus(found, total)
+		}
+	}
+}
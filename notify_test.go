@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// EmailNotifier tests
+// ===================
+
+func TestEmailNotifier_SendsSeatOpen(t *testing.T) {
+	mock := &MockEmailSender{}
+	n := &EmailNotifier{Sender: mock, To: "me@vt.edu"}
+
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.Sent) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(mock.Sent))
+	}
+	if got := mock.Sent[0].Body; got != "OPEN SEAT: Intro to Testing (CRN: 12345)" {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestEmailNotifier_IgnoresErrors(t *testing.T) {
+	mock := &MockEmailSender{}
+	n := &EmailNotifier{Sender: mock, To: "me@vt.edu"}
+
+	if err := n.Notify(Event{Kind: EventError, CRN: "12345"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mock.Sent) != 0 {
+		t.Errorf("expected no email for error event, got %d", len(mock.Sent))
+	}
+}
+
+// ===================
+// NtfyNotifier tests
+// ===================
+
+func TestNtfyNotifier_SeatOpen(t *testing.T) {
+	var gotPath, gotBody string
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer server.Close()
+
+	n := &NtfyNotifier{Config: NtfyConfig{Server: server.URL, Topic: "vt-seats", Token: "tk", Tags: []string{"school"}}}
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotPath != "/vt-seats" {
+		t.Errorf("path = %q, want /vt-seats", gotPath)
+	}
+	if gotBody != "OPEN SEAT: Intro to Testing (CRN: 12345)" {
+		t.Errorf("body = %q", gotBody)
+	}
+	if got := gotHeader.Get("Priority"); got != "5" {
+		t.Errorf("Priority = %q, want 5", got)
+	}
+	if got := gotHeader.Get("Tags"); got != "school,tada" {
+		t.Errorf("Tags = %q, want school,tada", got)
+	}
+	if got := gotHeader.Get("Click"); got != RegistrationURL {
+		t.Errorf("Click = %q, want %q", got, RegistrationURL)
+	}
+	if got := gotHeader.Get("Authorization"); got != "Bearer tk" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func TestNtfyNotifier_ErrorIsLowPriority(t *testing.T) {
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+	}))
+	defer server.Close()
+
+	n := &NtfyNotifier{Config: NtfyConfig{Server: server.URL, Topic: "vt-seats"}}
+	if err := n.Notify(Event{Kind: EventError, CRN: "12345", Message: "boom"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := gotHeader.Get("Priority"); got != "2" {
+		t.Errorf("Priority = %q, want 2", got)
+	}
+	if got := gotHeader.Get("Click"); got != "" {
+		t.Errorf("Click = %q, want empty for errors", got)
+	}
+}
+
+func TestNtfyNotifier_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	n := &NtfyNotifier{Config: NtfyConfig{Server: server.URL, Topic: "vt-seats"}}
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345"}); err == nil {
+		t.Error("expected error for 403 status")
+	}
+}
+
+// ===================
+// GotifyNotifier tests
+// ===================
+
+func TestGotifyNotifier_SeatOpen(t *testing.T) {
+	var gotKey string
+	var msg struct {
+		Title    string         `json:"title"`
+		Message  string         `json:"message"`
+		Priority int            `json:"priority"`
+		Extras   map[string]any `json:"extras"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/message" {
+			t.Errorf("path = %q, want /message", r.URL.Path)
+		}
+		gotKey = r.Header.Get("X-Gotify-Key")
+		json.NewDecoder(r.Body).Decode(&msg)
+	}))
+	defer server.Close()
+
+	n := &GotifyNotifier{Config: GotifyConfig{Server: server.URL, Token: "app-token"}}
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if gotKey != "app-token" {
+		t.Errorf("X-Gotify-Key = %q", gotKey)
+	}
+	if msg.Priority != 10 {
+		t.Errorf("priority = %d, want 10", msg.Priority)
+	}
+	if _, ok := msg.Extras["client::notification"]; !ok {
+		t.Error("expected click action in extras")
+	}
+}
+
+func TestGotifyNotifier_MissingToken(t *testing.T) {
+	n := &GotifyNotifier{Config: GotifyConfig{Server: "http://localhost"}}
+	if err := n.Notify(Event{Kind: EventSeatOpen}); err == nil {
+		t.Error("expected error when token is empty")
+	}
+}
+
+func TestPushChannels_SendErrorsOncePerStreak(t *testing.T) {
+	for _, n := range []eventFilter{&NtfyNotifier{}, &GotifyNotifier{}, &TelegramNotifier{}} {
+		var sent []int
+		for failures := 1; failures <= 5; failures++ {
+			if n.Accepts(Event{Kind: EventError, CRN: "12345", Failures: failures}) {
+				sent = append(sent, failures)
+			}
+		}
+		if len(sent) != 1 || sent[0] != defaultErrorThreshold {
+			t.Errorf("%T sent errors at failures %v, want only %d", n, sent, defaultErrorThreshold)
+		}
+		if !n.Accepts(Event{Kind: EventSeatOpen, CRN: "12345"}) {
+			t.Errorf("%T should accept seat openings", n)
+		}
+	}
+
+	n := &NtfyNotifier{Config: NtfyConfig{ErrorThreshold: 1}}
+	if !n.Accepts(Event{Kind: EventError, Failures: 1}) {
+		t.Error("expected a configured threshold of 1 to push the first failure")
+	}
+}
+
+// ===================
+// Dispatcher tests
+// ===================
+
+// countingNotifier records the events it was asked to deliver
+type countingNotifier struct {
+	name   string
+	sent   int
+	events []Event
+}
+
+func (c *countingNotifier) Name() string { return c.name }
+
+func (c *countingNotifier) Notify(ev Event) error {
+	c.sent++
+	c.events = append(c.events, ev)
+	return nil
+}
+
+func TestDispatcher_SuppressesRepeatsWithinCooldown(t *testing.T) {
+	push := &countingNotifier{name: "ntfy"}
+	sms := &countingNotifier{name: "sms"}
+	d := NewDispatcher([]Notifier{push, sms}, AlertPolicy{Cooldown: 60, ChannelCooldowns: map[string]int{"ntfy": 0}})
+
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+
+	ev := Event{Kind: EventSeatOpen, CRN: "12345"}
+	d.Dispatch(ev)
+	d.Flush()
+	now = now.Add(30 * time.Second)
+	d.Dispatch(ev)
+	d.Flush()
+
+	if push.sent != 2 {
+		t.Errorf("ntfy (no cooldown) sent %d, want 2", push.sent)
+	}
+	if sms.sent != 1 {
+		t.Errorf("sms (60s cooldown) sent %d, want 1", sms.sent)
+	}
+
+	now = now.Add(31 * time.Second)
+	d.Dispatch(ev)
+	d.Flush()
+	if sms.sent != 2 {
+		t.Errorf("sms sent %d after cooldown expired, want 2", sms.sent)
+	}
+}
+
+func TestDispatcher_CooldownIsPerCRNAndEvent(t *testing.T) {
+	sms := &countingNotifier{name: "sms"}
+	d := NewDispatcher([]Notifier{sms}, AlertPolicy{Cooldown: 60})
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	d.Flush()
+	d.Dispatch(Event{Kind: EventSeatClosed, CRN: "12345"})
+
+	if sms.sent != 3 {
+		t.Errorf("sent %d, want 3 distinct events delivered", sms.sent)
+	}
+}
+
+func TestDispatcher_BatchesOpeningsPerCycle(t *testing.T) {
+	email := &countingNotifier{name: "email"}
+	d := NewDispatcher([]Notifier{email}, AlertPolicy{})
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Data Structures", Section: &Section{Course: "CS-3114", Seats: 2, Days: "MWF", Begin: "10:10AM", End: "11:00AM"}})
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890", Name: "Computer Systems", Section: &Section{Seats: -1}})
+	if email.sent != 0 {
+		t.Fatalf("expected openings to wait for Flush, got %d sends", email.sent)
+	}
+	d.Flush()
+
+	if email.sent != 1 {
+		t.Fatalf("expected 1 batched message, got %d", email.sent)
+	}
+	ev := email.events[0]
+	if got, want := ev.Title(), "Seats open: CS-3114 Data Structures, Computer Systems"; got != want {
+		t.Errorf("Title() = %q, want %q", got, want)
+	}
+	if !strings.Contains(ev.Body(), "CS-3114 Data Structures (CRN 12345) - 2 seats - MWF 10:10AM-11:00AM") {
+		t.Errorf("Body() = %q", ev.Body())
+	}
+	if got := ev.CRNs(); len(got) != 2 {
+		t.Errorf("CRNs() = %v", got)
+	}
+
+	d.Flush()
+	if email.sent != 1 {
+		t.Errorf("expected empty flush to send nothing, got %d sends", email.sent)
+	}
+}
+
+func TestDispatcher_UrgentChannelsAlertPerCRN(t *testing.T) {
+	email := &countingNotifier{name: "email"}
+	push := &countingNotifier{name: "ntfy"}
+	d := NewDispatcher([]Notifier{email, push}, AlertPolicy{UrgentChannels: []string{"ntfy"}})
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+
+	if push.sent != 2 {
+		t.Errorf("urgent channel sent %d, want 2 immediate alerts", push.sent)
+	}
+	d.Flush()
+	if email.sent != 1 || push.sent != 2 {
+		t.Errorf("after flush: email %d (want 1), ntfy %d (want 2)", email.sent, push.sent)
+	}
+}
@@ -3,12 +3,17 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -29,12 +34,22 @@ type EmailSender interface {
 	Send(to, subject, body string) error
 }
 
+// htmlEmailSender is implemented by senders that can send multipart text+HTML email
+type htmlEmailSender interface {
+	SendHTML(to, subject, text, html string) error
+}
+
 // ResendEmailSender is the production implementation using Resend API
 type ResendEmailSender struct {
 	APIKey string
 }
 
 func (r *ResendEmailSender) Send(to, subject, body string) error {
+	return r.SendHTML(to, subject, body, "")
+}
+
+// SendHTML sends an email with both plain-text and HTML parts
+func (r *ResendEmailSender) SendHTML(to, subject, text, html string) error {
 	if r.APIKey == "" {
 		return fmt.Errorf("RESEND_API_KEY not set")
 	}
@@ -44,10 +59,14 @@ func (r *ResendEmailSender) Send(to, subject, body string) error {
 		From:    "onboarding@resend.dev",
 		To:      []string{to},
 		Subject: subject,
-		Text:    body,
+		Text:    text,
+		Html:    html,
 	}
 
 	_, err := client.Emails.Send(params)
+	if errors.Is(err, resend.ErrRateLimit) {
+		return fmt.Errorf("%w: %v", errRateLimited, err)
+	}
 	return err
 }
 
@@ -57,31 +76,66 @@ func (r *ResendEmailSender) Send(to, subject, body string) error {
 
 // Config holds the runtime configuration for the course monitor
 type Config struct {
-	CRNs          []string `json:"crns"`          // Course Reference Number(s) to monitor
-	Email         string   `json:"email"`         // Email address for notifications (optional)
-	CheckInterval int      `json:"checkInterval"` // Time between availability checks
-	Term          string   `json:"term"`          // Term code (e.g., 202601 = Spring 2026)
-	Campus        string   `json:"campus"`        // Campus code (0 = Blacksburg)
-	BaseURL       string   `json:"baseUrl"`       // Timetable URL (optional, for testability) (defaults to timetable url)
+	CRNs          WatchList `json:"crns"`          // Course Reference Number(s) to monitor, each a CRN or an object with per-CRN settings
+	Email         string    `json:"email"`         // Email address for notifications (optional)
+	CheckInterval int       `json:"checkInterval"` // Time between availability checks
+	Term          Term      `json:"term"`          // Term code or name (e.g., 202601, "Spring 2026" or "next")
+	Campus        string    `json:"campus"`        // Campus code (0 = Blacksburg)
+	BaseURL       string    `json:"baseUrl"`       // Timetable URL (optional, for testability) (defaults to timetable url)
+	Continuous    bool      `json:"continuous"`    // Keep watching after a seat opens and alert on every reopening
+	ResendAPIKey  Secret    `json:"resendApiKey"`  // Resend API key for email (optional) (defaults to $RESEND_API_KEY)
+
+	Alerts     AlertPolicy       `json:"alerts"`     // Confirmation and cooldown rules for alerts (optional)
+	Digest     *DigestConfig     `json:"digest"`     // Periodic status report schedule (optional)
+	Heartbeat  *HeartbeatConfig  `json:"heartbeat"`  // Liveness pings and monitor failure alerts (optional)
+	Outbox     string            `json:"outbox"`     // File for undelivered notifications (defaults to outbox.json)
+	QuietHours *QuietHoursConfig `json:"quietHours"` // Per-channel quiet hours and critical CRNs (optional)
+	Escalation *EscalationConfig `json:"escalation"` // Escalate unacknowledged openings across channels (optional)
+	Templates  string            `json:"templates"`  // Directory of notification templates (optional)
+	Quotas     *QuotaConfig      `json:"quotas"`     // Per-channel message limits and fallbacks (optional)
+
+	Ntfy     *NtfyConfig     `json:"ntfy"`     // ntfy push notifications (optional)
+	Gotify   *GotifyConfig   `json:"gotify"`   // Gotify push notifications (optional)
+	Telegram *TelegramConfig `json:"telegram"` // Telegram bot alerts and commands (optional)
+	SMS      *SMSConfig      `json:"sms"`      // Twilio text message alerts (optional)
+	Desktop  *DesktopConfig  `json:"desktop"`  // Desktop notifications and terminal signals (optional)
+	Exec     *ExecConfig     `json:"exec"`     // Script to run on monitor events (optional)
+
+	Profiles []ProfileConfig `json:"profiles"` // Other people's watches and channels, sharing one monitor (optional)
+
+	secretEnv []string // variables named by env: references, kept from exec hooks
 }
 
 type CourseStatus struct {
-	CRN   string
-	Name  string
-	Found bool
+	CRN        string
+	Name       string
+	Found      bool // done watching: a seat was found (or registered for, in continuous mode)
+	Open       bool // seats were available at the last check
+	OpenStreak int  // consecutive checks that found open seats
+	Failures   int  // consecutive failed checks
+	Stats      CourseStats
 }
 
+// loadConfig reads the config file at path, with environment overrides applied
 func loadConfig(path string) (Config, error) {
-	data, err := os.ReadFile(path)
+	return loadConfigFrom(ConfigSources{Path: path})
+}
+
+// loadConfigFrom layers the config file, OPENSEAT_* environment variables and
+// command-line flags (in increasing precedence), then fills in defaults and validates
+func loadConfigFrom(src ConfigSources) (Config, error) {
+	cfg, errs, err := readConfig(src)
 	if err != nil {
-		return Config{}, fmt.Errorf("failed to read config file: %w", err)
+		return Config{}, err
 	}
-
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return Config{}, fmt.Errorf("failed to parse config file: %w", err)
+	lookupEnv := src.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
 	}
 
+	// file:, env: and cmd: references become the secrets they point to
+	errs = append(errs, resolveSecrets(&cfg, lookupEnv)...)
+
 	// set defaults
 	if cfg.CheckInterval == 0 {
 		cfg.CheckInterval = 30
@@ -92,17 +146,59 @@ func loadConfig(path string) (Config, error) {
 	if cfg.Term == "" {
 		cfg.Term = "202601"
 	}
+	// names like "Fall 2026" and "next" become term codes; validate reports any that don't parse
+	if term, err := ParseTerm(string(cfg.Term), time.Now()); err == nil {
+		cfg.Term = term
+	}
 	if cfg.BaseURL == "" {
 		cfg.BaseURL = DefaultTimetableURL
 	}
-
-	if len(cfg.CRNs) == 0 {
-		return Config{}, fmt.Errorf("no CRNs specified in config")
+	if cfg.Outbox == "" {
+		cfg.Outbox = DefaultOutboxPath
 	}
 
+	// report every problem at once, before any network request. Values that
+	// failed to decode or resolve aren't checked again.
+	if errs = append(errs, cfg.validate().outside(errs)...); len(errs) > 0 {
+		return Config{}, errs
+	}
 	return cfg, nil
 }
 
+// readConfig layers the config file, environment variables and flags without
+// resolving secret references, filling in defaults or validating. Fields that
+// failed to decode are returned as errs.
+func readConfig(src ConfigSources) (cfg Config, errs ConfigErrors, err error) {
+	data, err := os.ReadFile(src.Path)
+	switch {
+	case err == nil:
+		// YAML and TOML are converted to JSON so every format is checked the same way
+		if data, err = configJSON(data, formatForPath(src.Path)); err != nil {
+			return Config{}, nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		if errs, err = decodeConfig(data, &cfg); err != nil {
+			return Config{}, nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		if err := checkSecretPermissions(src.Path, cfg); err != nil {
+			return Config{}, nil, err
+		}
+	case src.Optional && errors.Is(err, os.ErrNotExist):
+		// everything comes from the environment and flags
+	default:
+		return Config{}, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	lookupEnv := src.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	if err := applyEnv(&cfg, lookupEnv); err != nil {
+		return Config{}, nil, err
+	}
+	src.Flags.apply(&cfg)
+	return cfg, errs, nil
+}
+
 func (c Config) getBaseURL() string {
 	if c.BaseURL != "" {
 		return c.BaseURL
@@ -116,7 +212,7 @@ func (c Config) buildPayload(crn string, openOnly bool) url.Values {
 	// Initialize as a standard Go map
 	rawMap := map[string][]string{
 		"CAMPUS":           {c.Campus},
-		"TERMYEAR":         {c.Term},
+		"TERMYEAR":         {string(c.Term)},
 		"CORE_CODE":        {"AR%"},
 		"subj_code":        {"%"},
 		"SCHDTYPE":         {"%"},
@@ -162,17 +258,151 @@ func fetchDocument(targetUrl string, payload url.Values) (*goquery.Document, err
 	return doc, err
 }
 
-// checkSectionOpen checks if the configured course section has available seats.
-// Returns true if the section appears in open-only search results.
-func (c Config) checkSectionOpen(crn string) (bool, error) {
+// Section holds the timetable details for a single course section.
+// Fields missing from the timetable are left empty; Seats and Capacity are -1 when unknown.
+type Section struct {
+	CRN        string `json:"crn"`
+	Course     string `json:"course"`
+	Title      string `json:"title"`
+	Instructor string `json:"instructor"`
+	Days       string `json:"days"`
+	Begin      string `json:"begin"`
+	End        string `json:"end"`
+	Location   string `json:"location"`
+	Seats      int    `json:"seats"`
+	Capacity   int    `json:"capacity"`
+}
+
+// MeetingTime formats the section's days and times, e.g. "MWF 10:10AM-11:00AM"
+func (s Section) MeetingTime() string {
+	switch {
+	case s.Days == "":
+		return ""
+	case s.Begin == "":
+		return s.Days
+	default:
+		return fmt.Sprintf("%s %s-%s", s.Days, s.Begin, s.End)
+	}
+}
+
+// parseSection finds the row for crn in a timetable results page. Columns are
+// located by header text when a header row is present, so optional columns such
+// as Seats don't shift the others; otherwise the CRN/course/title positions are assumed.
+func parseSection(doc *goquery.Document, crn string) (Section, bool) {
+	var section Section
+	found := false
+	eachSectionRow(doc, func(cell func(name string) string) bool {
+		if !strings.Contains(cell("crn"), crn) {
+			return true
+		}
+		section = sectionFromRow(crn, cell)
+		found = true
+		return false
+	})
+	return section, found
+}
+
+// parseSections returns every section listed in a timetable results page
+func parseSections(doc *goquery.Document) []Section {
+	var sections []Section
+	eachSectionRow(doc, func(cell func(name string) string) bool {
+		if crn := sectionCRNPattern.FindString(cell("crn")); crn != "" {
+			sections = append(sections, sectionFromRow(crn, cell))
+		}
+		return true
+	})
+	return sections
+}
+
+// sectionCRNPattern finds the CRN in a results row's first cell
+var sectionCRNPattern = regexp.MustCompile(`\b[0-9]{5}\b`)
+
+// eachSectionRow calls fn with a cell lookup (by lowercased header name) for
+// each row of timetable results, until fn returns false
+func eachSectionRow(doc *goquery.Document, fn func(cell func(name string) string) bool) {
+	columns := map[string]int{"crn": 0, "course": 1, "title": 2}
+	headerSeen := false
+
+	doc.Find(".dataentrytable tr").EachWithBreak(func(i int, row *goquery.Selection) bool {
+		cells := row.Find("th, td")
+		texts := make([]string, cells.Length())
+		cells.Each(func(j int, cell *goquery.Selection) {
+			texts[j] = strings.Join(strings.Fields(cell.Text()), " ")
+		})
+		if len(texts) == 0 {
+			return true
+		}
+
+		if !headerSeen && strings.EqualFold(texts[0], "CRN") {
+			headerSeen = true
+			columns = map[string]int{}
+			for j, text := range texts {
+				columns[strings.ToLower(text)] = j
+			}
+			return true
+		}
+
+		return fn(func(name string) string {
+			if j, ok := columns[name]; ok && j < len(texts) {
+				return texts[j]
+			}
+			return ""
+		})
+	})
+}
+
+// sectionFromRow builds a section from a results row
+func sectionFromRow(crn string, get func(name string) string) Section {
+	return Section{
+		CRN:        crn,
+		Course:     get("course"),
+		Title:      get("title"),
+		Instructor: get("instructor"),
+		Days:       get("days"),
+		Begin:      get("begin"),
+		End:        get("end"),
+		Location:   get("location"),
+		Seats:      parseCount(get("seats")),
+		Capacity:   parseCount(get("capacity")),
+	}
+}
+
+// parseCount reads the leading number from a seat count cell, returning -1 if there isn't one
+func parseCount(text string) int {
+	for _, field := range strings.Fields(text) {
+		if n, err := strconv.Atoi(field); err == nil {
+			return n
+		}
+	}
+	return -1
+}
+
+// resendAPIKey returns the configured Resend API key, falling back to $RESEND_API_KEY
+func (c Config) resendAPIKey() string {
+	if c.ResendAPIKey != "" {
+		return c.ResendAPIKey.Value()
+	}
+	return os.Getenv("RESEND_API_KEY")
+}
+
+// checkSection fetches open-only results for crn.
+// Returns the section details and true if the section has available seats.
+func (c Config) checkSection(crn string) (Section, bool, error) {
 	payload := c.buildPayload(crn, true)
 	doc, err := fetchDocument(c.getBaseURL(), payload)
 	if err != nil {
-		return false, err
+		return Section{}, false, err
 	}
 
-	table := doc.Find(".dataentrytable").Text()
-	return strings.Contains(table, crn), nil
+	section, open := parseSection(doc, crn)
+	return section, open, nil
+}
+
+// checkSectionOpen checks if the configured course section has available seats.
+// Returns true if the section appears in open-only search results.
+func (c Config) checkSectionOpen(crn string) (bool, error) {
+	_, open, err := c.checkSection(crn)
+	return open, err
 }
 
 // getCourseName retrieves the course title for the configured CRN.
@@ -200,30 +430,16 @@ func (c Config) getCourseName(crn string) (string, error) {
 	return courseName, nil
 }
 
-// =================================
-// Notifications
-// =================================
-
-// sendEmail sends a notification email using the Resend API.
-// Requires RESEND_API_KEY environment varialbe to be set.
-func sendEmail(to, subject, body string) error {
-	apiKey := os.Getenv("RESEND_API_KEY")
-	if apiKey == "" {
-		return fmt.Errorf("RESEND_API_KEY not set")
-	}
-
-	client := resend.NewClient(apiKey)
-
-	params := &resend.SendEmailRequest{
-		From:    "onboarding@resend.dev",
-		To:      []string{to},
-		Subject: subject,
-		Text:    body,
-		// Html: "<p>Hello, World!</p>",
+// searchSections lists every section of a course, e.g. subject "CS" and number "3114"
+func (c Config) searchSections(subject, number string) ([]Section, error) {
+	payload := c.buildPayload("", false)
+	payload.Set("subj_code", strings.ToUpper(subject))
+	payload.Set("CRSE_NUMBER", number)
+	doc, err := fetchDocument(c.getBaseURL(), payload)
+	if err != nil {
+		return nil, err
 	}
-
-	_, err := client.Emails.Send(params)
-	return err
+	return parseSections(doc), nil
 }
 
 // ===================================
@@ -231,37 +447,87 @@ func sendEmail(to, subject, body string) error {
 // ===================================
 
 type RunOptions struct {
-	ConfigPath  string
-	EmailSender EmailSender
+	ConfigPath     string
+	ConfigOptional bool            // run from environment and flags alone when the file is missing
+	Overrides      ConfigOverrides // settings from command-line flags
+	EmailSender    EmailSender
 }
 
-func Run(opts RunOptions) error {
-	cfg, err := loadConfig(opts.ConfigPath)
+func Run(opts RunOptions) (err error) {
+	// secrets from .env files become environment variables before the config is layered
+	envSources, err := LoadDotenv(dotenvPaths(opts.ConfigPath))
+	if err != nil {
+		return fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	sources := ConfigSources{Path: opts.ConfigPath, Optional: opts.ConfigOptional, Flags: opts.Overrides}
+	cfg, err := loadConfigFrom(sources)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	watcher := NewConfigWatcher(sources)
 
 	// use provided email sender or create default
 	emailSender := opts.EmailSender
 	if emailSender == nil {
-		emailSender = &ResendEmailSender{APIKey: os.Getenv("RESEND_API_KEY")}
+		emailSender = &ResendEmailSender{APIKey: cfg.resendAPIKey()}
+	}
+
+	// each profile gets its own channels; CRNs are polled once and fanned out
+	dispatcher, err := NewFanout(cfg, emailSender)
+	if err != nil {
+		return err
+	}
+	defer dispatcher.Wait() // let running hooks finish before exiting
+
+	// Stop cleanly on Ctrl+C or SIGTERM so the exit notice can go out
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var heartbeat *Heartbeat
+	if cfg.Heartbeat != nil {
+		heartbeat = &Heartbeat{Config: *cfg.Heartbeat}
+		if cfg.Heartbeat.NotifyOnExit {
+			defer func() {
+				if r := recover(); r != nil {
+					dispatcher.Dispatch(exitEvent(fmt.Sprintf("crashed: %v", r)))
+					panic(r)
+				}
+				reason := "all courses found"
+				switch {
+				case err != nil:
+					reason = err.Error()
+				case ctx.Err() != nil:
+					reason = "interrupted"
+				}
+				dispatcher.Dispatch(exitEvent(reason))
+			}()
+		}
 	}
 
 	// Display UI
 	PrintBanner()
-	PrintConfigBox(len(cfg.CRNs), cfg.Email, cfg.CheckInterval, cfg.Term)
+	watches := cfg.watchList()
+	PrintConfigBox(len(watches), cfg.Email, cfg.CheckInterval, cfg.Term)
+	PrintProfiles(cfg.profiles())
+	PrintDotenvSources(envSources)
 
 	// Initialize course statuses - filter out invalid CRNs
 	PrintFetchingHeader()
 	var courses []CourseStatus
-	for _, crn := range cfg.CRNs {
-		name, err := cfg.getCourseName(crn)
+	for _, watch := range watches {
+		if watch.expired(time.Now()) {
+			PrintWatchExpired(watch.CRN)
+			continue
+		}
+		name, err := cfg.getCourseName(watch.CRN)
 		if err != nil {
-			PrintCourseNotFound(crn)
+			PrintCourseNotFound(watch.CRN)
 			continue
 		}
-		courses = append(courses, CourseStatus{CRN: crn, Name: name, Found: false})
-		PrintCourseFound(crn, name)
+		name = watch.displayName(name)
+		courses = append(courses, CourseStatus{CRN: watch.CRN, Name: name, Found: false})
+		PrintCourseFound(watch.CRN, name)
 	}
 
 	if len(courses) == 0 {
@@ -270,55 +536,182 @@ func Run(opts RunOptions) error {
 
 	PrintDivider()
 
+	monitor := NewMonitor(cfg, courses)
+	monitor.escalator = dispatcher
+	dispatcher.SetCourses(monitor.Status)
+
+	// Start listening for remote commands on channels that support them
+	stop := make(chan struct{})
+	defer close(stop)
+	commands := &listeners{}
+	commands.sync(dispatcher.Listeners(monitor))
+	defer commands.stopAll()
+	go (&consoleCommands{in: os.Stdin}).Listen(stop, monitor)
+	go func() {
+		if err := dispatcher.ServeAcks(stop); err != nil {
+			PrintNotifyError("escalation", err)
+		}
+	}()
+
 	// Main monitoring loop
-	remaining := len(courses)
-	interval := time.Duration(cfg.CheckInterval) * time.Second
+	var nextDigest time.Time
+	if cfg.Digest != nil {
+		nextDigest = cfg.Digest.next(time.Now())
+	}
+
+	// each CRN is checked on its own interval
+	lastChecked := map[string]time.Time{}
 
 	for attempt := 1; ; attempt++ {
 		checkTime := time.Now().Format("15:04:05")
 
-		for i := range courses {
-			if courses[i].Found {
+		for _, course := range monitor.Status() {
+			// a CRN every watching profile has paused or got isn't checked
+			if course.Found || monitor.Paused() || !dispatcher.Wanted(course.CRN) {
 				continue
 			}
-
-			PrintCheckingStatus(attempt, attempt, courses[i].CRN)
-
-			open, err := cfg.checkSectionOpen(courses[i].CRN)
-			if err != nil {
-				PrintCheckError(checkTime, courses[i].CRN, err)
+			now := time.Now()
+			watch := watches.Get(course.CRN)
+			if watch.expired(now) {
+				monitor.RemoveCRN(course.CRN)
+				monitor.Acknowledge(course.CRN)
+				PrintWatchExpired(course.CRN)
+				continue
+			}
+			if now.Before(lastChecked[course.CRN].Add(watch.interval(cfg.CheckInterval))) {
 				continue
 			}
+			lastChecked[course.CRN] = now
 
-			if open {
-				courses[i].Found = true
-				remaining--
+			PrintCheckingStatus(attempt, attempt, course.CRN)
 
-				PrintSeatAvailable(courses[i].Name, courses[i].CRN)
+			pollCourse(cfg, monitor, dispatcher, course, checkTime)
 
-				if cfg.Email != "" {
-					sendEmail(cfg.Email, "VT Course Section Open!", fmt.Sprintf("OPEN SEAT: %s (CRN: %s)", courses[i].Name, courses[i].CRN))
-					PrintEmailSent(cfg.Email)
-				}
+			if !sleepContext(ctx, 500*time.Millisecond) { // Small delay between requests
+				break
+			}
+		}
+
+		dispatcher.Flush()
+		dispatcher.RetryDue()
+		if ctx.Err() != nil {
+			PrintInterrupted()
+			return nil
+		}
+
+		if heartbeat != nil {
+			if alert := heartbeat.Tick(time.Now(), monitor.failureStreak()); alert != nil {
+				PrintUnhealthy(alert.Failures)
+				dispatcher.Dispatch(*alert)
 			}
+		}
 
-			time.Sleep(500 * time.Millisecond) // Small delay between requests
+		if !nextDigest.IsZero() && !time.Now().Before(nextDigest) {
+			dispatcher.Dispatch(buildDigest(monitor.Status(), monitor.started, time.Now()))
+			PrintDigestSent(cfg.Digest.Schedule)
+			nextDigest = cfg.Digest.next(time.Now())
 		}
 
-		if remaining == 0 {
+		found, total := monitor.counts()
+		dispatcher.ReportStatus(found, total)
+		if total > 0 && found == total && !dispatcher.Escalating() {
 			PrintAllCoursesFound()
 			return nil
 		}
 
 		// Animate spinner while waiting
-		waitUntil := time.Now().Add(interval)
+		waitUntil := nextCheck(monitor.Status(), lastChecked, watches, cfg.CheckInterval, time.Now())
 		i := 0
 		for time.Now().Before(waitUntil) {
 			timeLeft := time.Until(waitUntil).Round(time.Second)
-			found := len(courses) - remaining
-			PrintWaitingStatus(i, attempt, found, len(courses), timeLeft.String(), checkTime)
-			time.Sleep(100 * time.Millisecond)
+			found, total := monitor.counts()
+			if monitor.Paused() {
+				PrintPausedStatus(i, found, total)
+			} else {
+				PrintWaitingStatus(i, attempt, found, total, timeLeft.String(), checkTime)
+			}
+			if !sleepContext(ctx, 100*time.Millisecond) {
+				PrintInterrupted()
+				return nil
+			}
+			if i%10 == 0 {
+				dispatcher.Escalate()
+				if heartbeat != nil {
+					heartbeat.Ping(time.Now())
+				}
+
+				// pick up edits to the config file without losing the watch list
+				if next, changed, err := watcher.Poll(); err != nil {
+					PrintConfigReloadError(opts.ConfigPath, err)
+				} else if changed {
+					diff := diffConfig(cfg, next)
+					if err := applyConfig(cfg, next, diff, monitor, dispatcher); err != nil {
+						PrintConfigReloadError(opts.ConfigPath, err)
+					} else if !diff.Empty() {
+						PrintConfigReloaded(opts.ConfigPath, diff)
+						commands.sync(dispatcher.Listeners(monitor))
+						if diff.Touches("digest") {
+							nextDigest = time.Time{}
+							if next.Digest != nil {
+								nextDigest = next.Digest.next(time.Now())
+							}
+						}
+						cfg, watches = next, next.watchList()
+						waitUntil = nextCheck(monitor.Status(), lastChecked, watches, cfg.CheckInterval, time.Now())
+					}
+				}
+			}
 			i++
 		}
 	}
 }
+
+// sleepContext waits for d, returning false early if ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// pollCourse checks a single course and sends notifications for any state change
+func pollCourse(cfg Config, monitor *Monitor, dispatcher eventDispatcher, course CourseStatus, checkTime string) {
+	watch := cfg.watchList().Get(course.CRN)
+	section, listed, err := cfg.checkSection(course.CRN)
+	failures := monitor.recordCheck(course.CRN, err)
+	if err != nil {
+		PrintCheckError(checkTime, course.CRN, err)
+		dispatcher.Dispatch(Event{Kind: EventError, CRN: course.CRN, Name: course.Name, Message: err.Error(), Time: time.Now(), Failures: failures})
+		return
+	}
+
+	// sections with fewer open seats than the watch's threshold count as closed
+	open := listed && (section.Seats < 0 || section.Seats >= watch.minSeats())
+	change, streak := monitor.observe(course.CRN, open)
+	if listed {
+		monitor.recordSeats(course.CRN, section.Seats)
+	}
+	if listed && !open && change == unchanged && !course.Open {
+		PrintSuppressed(course.CRN, fmt.Sprintf("%d seats open, waiting for %d", section.Seats, watch.minSeats()))
+	}
+
+	switch change {
+	case openPending:
+		PrintSuppressed(course.CRN, fmt.Sprintf("open %d/%d checks, waiting to confirm", streak, max(cfg.Alerts.ConfirmChecks, 1)))
+	case opened:
+		// without continuous mode, the first opening finishes the CRN
+		if !cfg.Continuous {
+			monitor.markFound(course.CRN)
+		}
+
+		PrintSeatAvailable(course.Name, course.CRN)
+
+		dispatcher.Dispatch(Event{Kind: EventSeatOpen, CRN: course.CRN, Name: course.Name, Time: time.Now(), Section: &section})
+	case closed:
+		PrintSeatClosed(course.Name, course.CRN)
+
+		dispatcher.Dispatch(Event{Kind: EventSeatClosed, CRN: course.CRN, Name: course.Name, Time: time.Now()})
+	}
+}
@@ -264,6 +264,53 @@ func TestCheckSectionOpen_ServerError(t *testing.T) {
 	}
 }
 
+// ===================
+// parseSection tests
+// ===================
+
+func TestCheckSection_ParsesColumnsByHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`
+			<table class="dataentrytable">
+				<tr><th>CRN</th><th>Course</th><th>Title</th><th>Seats</th><th>Capacity</th><th>Instructor</th><th>Days</th><th>Begin</th><th>End</th><th>Location</th></tr>
+				<tr><td>12345</td><td>CS-3114</td><td>Data Structures</td><td>3</td><td>75</td><td>Smith</td><td>M W F</td><td>10:10AM</td><td>11:00AM</td><td>MCB 100</td></tr>
+			</table>
+		`))
+	}))
+	defer server.Close()
+
+	cfg := Config{BaseURL: server.URL, Campus: "0", Term: "202601"}
+	section, open, err := cfg.checkSection("12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !open {
+		t.Fatal("expected open=true")
+	}
+	if section.Seats != 3 || section.Capacity != 75 {
+		t.Errorf("seats/capacity = %d/%d, want 3/75", section.Seats, section.Capacity)
+	}
+	if got := section.MeetingTime(); got != "M W F 10:10AM-11:00AM" {
+		t.Errorf("MeetingTime() = %q", got)
+	}
+}
+
+func TestCheckSection_UnknownSeatsWithoutHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<table class="dataentrytable"><tr><td>12345</td><td>CS-3114</td><td>Data Structures</td></tr></table>`))
+	}))
+	defer server.Close()
+
+	cfg := Config{BaseURL: server.URL, Campus: "0", Term: "202601"}
+	section, _, err := cfg.checkSection("12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if section.Title != "Data Structures" || section.Seats != -1 {
+		t.Errorf("unexpected section: %+v", section)
+	}
+}
+
 // ===================
 // getCourseName tests
 // ===================
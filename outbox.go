@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultOutboxPath is where undelivered notifications are kept between runs
+const DefaultOutboxPath = "outbox.json"
+
+// outboxMaxAttempts is how many deliveries are tried before an entry is marked failed
+const outboxMaxAttempts = 8
+
+// Outbox entry states
+const (
+	outboxPending = "pending"
+	outboxFailed  = "failed"
+)
+
+// OutboxEntry is a notification waiting to be delivered to one channel
+type OutboxEntry struct {
+	ID          string    `json:"id"`
+	Channel     string    `json:"channel"`
+	Event       Event     `json:"event"`
+	State       string    `json:"state"`
+	Attempts    int       `json:"attempts"`
+	Created     time.Time `json:"created"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+}
+
+// Outbox is an on-disk queue of notifications. Entries are written before
+// delivery and removed after success, so anything interrupted by a network
+// outage or restart is retried rather than lost.
+type Outbox struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries []OutboxEntry
+	seq     int
+}
+
+// OpenOutbox loads the outbox at path, creating an empty one if it doesn't exist
+func OpenOutbox(path string) (*Outbox, error) {
+	o := &Outbox{path: path, now: time.Now}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &o.entries); err != nil {
+			return nil, fmt.Errorf("failed to parse outbox %s: %w", path, err)
+		}
+	}
+	return o, nil
+}
+
+// Add records a notification before it is delivered and returns its entry ID
+func (o *Outbox) Add(channel string, ev Event) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.add(channel, ev, o.now())
+}
+
+// Hold records an opening that mustn't go out before until. Openings held for
+// the same channel and time are merged, so they're sent as one summary.
+func (o *Outbox) Hold(channel string, ev Event, until time.Time) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := range o.entries {
+		e := &o.entries[i]
+		if e.Channel != channel || e.State != outboxPending || e.Attempts > 0 || !e.NextAttempt.Equal(until) {
+			continue
+		}
+		covers := e.Event.covers()
+		if !slices.ContainsFunc(covers, func(c Event) bool { return c.DedupeKey() == ev.DedupeKey() }) {
+			e.Event = batchEvent(append(slices.Clone(covers), ev))
+		}
+		return e.ID, o.save()
+	}
+	return o.add(channel, ev, until)
+}
+
+// add appends a pending entry first attempted at notBefore. Callers must hold o.mu.
+func (o *Outbox) add(channel string, ev Event, notBefore time.Time) (string, error) {
+	now := o.now()
+	o.seq++
+	entry := OutboxEntry{
+		ID:          fmt.Sprintf("%d-%d", now.UnixNano(), o.seq),
+		Channel:     channel,
+		Event:       ev,
+		State:       outboxPending,
+		Created:     now,
+		NextAttempt: notBefore,
+	}
+	o.entries = append(o.entries, entry)
+	return entry.ID, o.save()
+}
+
+// Done removes a delivered entry
+func (o *Outbox) Done(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, e := range o.entries {
+		if e.ID == id {
+			o.entries = append(o.entries[:i], o.entries[i+1:]...)
+			return o.save()
+		}
+	}
+	return nil
+}
+
+// Fail records a failed delivery and schedules a retry with exponential backoff.
+// After outboxMaxAttempts the entry is kept as failed for inspection but not retried.
+func (o *Outbox) Fail(id string, deliveryErr error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i := range o.entries {
+		e := &o.entries[i]
+		if e.ID != id {
+			continue
+		}
+		e.Attempts++
+		e.LastError = deliveryErr.Error()
+		if e.Attempts >= outboxMaxAttempts {
+			e.State = outboxFailed
+		} else {
+			e.NextAttempt = o.now().Add(outboxBackoff(e.Attempts))
+		}
+		return o.save()
+	}
+	return nil
+}
+
+// outboxBackoff returns the wait before the next attempt: 30s doubling up to an hour
+func outboxBackoff(attempts int) time.Duration {
+	delay := 30 * time.Second << (attempts - 1)
+	if delay <= 0 || delay > time.Hour {
+		return time.Hour
+	}
+	return delay
+}
+
+// Due returns pending entries whose next attempt time has passed
+func (o *Outbox) Due() []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	var due []OutboxEntry
+	for _, e := range o.entries {
+		if e.State == outboxPending && !e.NextAttempt.After(now) {
+			due = append(due, e)
+		}
+	}
+	return due
+}
+
+// Entries returns every entry, oldest first
+func (o *Outbox) Entries() []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]OutboxEntry(nil), o.entries...)
+}
+
+// Purge removes entries in the given state ("" removes everything) and returns how many were removed
+func (o *Outbox) Purge(state string) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	kept := o.entries[:0]
+	for _, e := range o.entries {
+		if state != "" && e.State != state {
+			kept = append(kept, e)
+		}
+	}
+	removed := len(o.entries) - len(kept)
+	o.entries = kept
+	return removed, o.save()
+}
+
+// save atomically rewrites the outbox file. Callers must hold o.mu.
+func (o *Outbox) save() error {
+	data, err := json.MarshalIndent(o.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox: %w", err)
+	}
+
+	if err := writeFileAtomic(o.path, data); err != nil {
+		return fmt.Errorf("failed to write outbox: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the
+// same directory, so a crash never leaves a half-written file behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ===================================
+// `openseat outbox` command
+// ===================================
+
+// RunOutboxCommand implements `openseat outbox [list|purge]` for inspecting
+// and clearing undelivered notifications. Without --file it works on the
+// outbox of every profile in the config the monitor would load.
+func RunOutboxCommand(args []string, lookupEnv func(string) (string, bool), out io.Writer) error {
+	fs := flag.NewFlagSet("outbox", flag.ContinueOnError)
+	fs.SetOutput(out)
+	path := fs.String("file", "", "outbox file (default: each profile's outbox from the config)")
+	configPath := fs.String("config", "", "config file to find the outboxes in (default config.json, or $OPENSEAT_CONFIG)")
+	state := fs.String("state", "", "only purge entries in this state (pending or failed)")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "Usage: openseat outbox [list|purge] [--config path | --file path] [--state pending|failed]")
+		fs.PrintDefaults()
+	}
+
+	action := "list"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		action, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch action {
+	case "list":
+	case "purge":
+		if *state != "" && *state != outboxPending && *state != outboxFailed {
+			return fmt.Errorf("unknown state %q", *state)
+		}
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown outbox action %q", action)
+	}
+
+	profiles := []profile{{Name: defaultProfile, Config: Config{Outbox: *path}}}
+	if *path == "" {
+		var err error
+		if profiles, err = configOutboxes(*configPath, lookupEnv); err != nil {
+			return err
+		}
+	}
+
+	for i, p := range profiles {
+		outbox, err := OpenOutbox(p.Config.Outbox)
+		if err != nil {
+			return err
+		}
+		if len(profiles) > 1 {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "%s (%s):\n", p.Name, p.Config.Outbox)
+		}
+
+		if action == "list" {
+			writeOutboxEntries(out, outbox.Entries())
+			continue
+		}
+		removed, err := outbox.Purge(*state)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Removed %d entries from %s\n", removed, p.Config.Outbox)
+	}
+	return nil
+}
+
+// configOutboxes loads the config at flagValue (or the monitor's default) and
+// returns its profiles, whose configs name their outbox files. Without a
+// config file, the default outbox is used.
+func configOutboxes(flagValue string, lookupEnv func(string) (string, bool)) ([]profile, error) {
+	path, optional := configLocation(flagValue, lookupEnv)
+	if _, err := os.Stat(path); optional && errors.Is(err, os.ErrNotExist) {
+		return []profile{{Name: defaultProfile, Config: Config{Outbox: DefaultOutboxPath}}}, nil
+	}
+	// OPENSEAT_OUTBOX may be set in .env
+	if _, err := LoadDotenv(dotenvPaths(path)); err != nil {
+		return nil, err
+	}
+	// only the outbox paths are needed, so secret references aren't resolved
+	// and no cmd: command runs
+	cfg, errs, err := readConfig(ConfigSources{Path: path, LookupEnv: lookupEnv})
+	if err == nil && len(errs) > 0 {
+		err = errs
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the outbox (use --file to name it): %w", err)
+	}
+	if cfg.Outbox == "" {
+		cfg.Outbox = DefaultOutboxPath
+	}
+	return cfg.profiles(), nil
+}
+
+// writeOutboxEntries writes a listing of outbox entries, grouped by state
+func writeOutboxEntries(out io.Writer, entries []OutboxEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "Outbox is empty.")
+		return
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].State > entries[j].State })
+	for _, e := range entries {
+		fmt.Fprintf(out, "%s  %-8s %-8s %-11s attempts %d  %s\n",
+			e.Created.Format("Jan 2 15:04"), e.State, e.Channel, e.Event.Kind, e.Attempts, e.Event.Title())
+		if e.LastError != "" {
+			fmt.Fprintf(out, "    last error: %s\n", e.LastError)
+		}
+		if e.State == outboxPending {
+			fmt.Fprintf(out, "    next attempt: %s\n", e.NextAttempt.Format("Jan 2 15:04:05"))
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// Outbox tests
+// ===================
+
+// flakyNotifier fails until told to succeed
+type flakyNotifier struct {
+	name string
+	fail bool
+	sent []Event
+}
+
+func (f *flakyNotifier) Name() string { return f.name }
+
+func (f *flakyNotifier) Notify(ev Event) error {
+	if f.fail {
+		return errors.New("connection refused")
+	}
+	f.sent = append(f.sent, ev)
+	return nil
+}
+
+func openTestOutbox(t *testing.T, now *time.Time) *Outbox {
+	t.Helper()
+	o, err := OpenOutbox(filepath.Join(t.TempDir(), "outbox.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.now = func() time.Time { return *now }
+	return o
+}
+
+func TestOutbox_FailBacksOffAndPersists(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	o := openTestOutbox(t, &now)
+
+	id, err := o.Add("ntfy", Event{Kind: EventSeatOpen, CRN: "12345", Name: "CS-3114"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Fail(id, errors.New("timeout")); err != nil {
+		t.Fatal(err)
+	}
+	if due := o.Due(); len(due) != 0 {
+		t.Fatalf("expected no entries due during backoff, got %d", len(due))
+	}
+
+	now = now.Add(30 * time.Second)
+	if due := o.Due(); len(due) != 1 {
+		t.Fatalf("expected entry due after 30s backoff, got %d", len(due))
+	}
+
+	reloaded, err := OpenOutbox(o.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := reloaded.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry after reload, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.Event.Kind != EventSeatOpen || e.Event.CRN != "12345" || e.Attempts != 1 || e.LastError != "timeout" {
+		t.Errorf("entry did not round-trip: %+v", e)
+	}
+}
+
+func TestOutbox_MarksFailedAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	o := openTestOutbox(t, &now)
+
+	id, _ := o.Add("sms", Event{Kind: EventSeatOpen, CRN: "12345"})
+	for range outboxMaxAttempts {
+		o.Fail(id, errors.New("down"))
+	}
+
+	now = now.Add(24 * time.Hour)
+	if due := o.Due(); len(due) != 0 {
+		t.Errorf("failed entries should not be retried, got %d due", len(due))
+	}
+	if entries := o.Entries(); len(entries) != 1 || entries[0].State != outboxFailed {
+		t.Errorf("expected entry kept as failed, got %+v", entries)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{6, 16 * time.Minute},
+		{8, time.Hour},
+		{40, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestDispatcher_RetriesFailedDeliveries(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	push := &flakyNotifier{name: "ntfy", fail: true}
+	d := NewDispatcher([]Notifier{push}, AlertPolicy{})
+	d.now = func() time.Time { return now }
+	d.outbox = openTestOutbox(t, &now)
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+	if len(d.outbox.Entries()) != 1 {
+		t.Fatal("expected failed delivery to stay in the outbox")
+	}
+
+	push.fail = false
+	now = now.Add(time.Minute)
+	d.RetryDue()
+
+	if len(push.sent) != 1 || push.sent[0].CRN != "12345" {
+		t.Errorf("expected retried seat-open delivery, got %+v", push.sent)
+	}
+	if entries := d.outbox.Entries(); len(entries) != 0 {
+		t.Errorf("expected outbox empty after retry, got %d entries", len(entries))
+	}
+}
+
+func TestDispatcher_RetriesRespectQuotasAndRouting(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	push := &flakyNotifier{name: "ntfy", fail: true}
+	d := NewDispatcher([]Notifier{push}, AlertPolicy{})
+	d.now = func() time.Time { return now }
+	d.outbox = openTestOutbox(t, &now)
+	d.quotas = openTestQuotas(t, map[string]ChannelQuota{"ntfy": {Daily: 1}})
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+	push.fail = false
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	d.Flush() // uses the day's only message
+
+	now = now.Add(time.Minute)
+	d.RetryDue()
+	if len(push.sent) != 1 {
+		t.Errorf("retry went past the daily quota: sent %+v", push.sent)
+	}
+	if entries := d.outbox.Entries(); len(entries) != 1 || entries[0].Event.CRN != "12345" {
+		t.Errorf("expected the blocked retry to stay in the outbox, got %+v", entries)
+	}
+
+	// a CRN routed away from the channel isn't retried there
+	d.quotas = nil
+	d.configure(func(s *dispatchSettings) { s.channels = map[string][]string{"12345": {"email"}} })
+	now = now.Add(time.Hour)
+	d.RetryDue()
+	if len(push.sent) != 1 || len(d.outbox.Entries()) != 0 {
+		t.Errorf("expected the retry dropped for a CRN not routed to ntfy, sent %+v", push.sent)
+	}
+}
+
+func TestDispatcher_RetriedBatchFollowsQuietHours(t *testing.T) {
+	now := time.Date(2026, 1, 10, 23, 0, 0, 0, time.Local)
+	sms := &flakyNotifier{name: "sms"}
+	d := NewDispatcher([]Notifier{sms}, AlertPolicy{})
+	d.now = func() time.Time { return now }
+	d.outbox = openTestOutbox(t, &now)
+	d.configure(func(s *dispatchSettings) {
+		s.quiet = &QuietHoursConfig{Channels: map[string]QuietWindow{"sms": {Start: "22:00", End: "07:00"}}, Critical: []string{"12345"}}
+	})
+
+	batch := batchEvent([]Event{{Kind: EventSeatOpen, CRN: "12345"}, {Kind: EventSeatOpen, CRN: "67890"}})
+	if _, err := d.outbox.Add("sms", batch); err != nil {
+		t.Fatal(err)
+	}
+	d.RetryDue()
+
+	if len(sms.sent) != 1 || sms.sent[0].CRN != "12345" {
+		t.Errorf("expected the critical CRN delivered during quiet hours, got %+v", sms.sent)
+	}
+	end := time.Date(2026, 1, 11, 7, 0, 0, 0, time.Local)
+	if entries := d.outbox.Entries(); len(entries) != 1 || entries[0].Event.CRN != "67890" || !entries[0].NextAttempt.Equal(end) {
+		t.Errorf("expected the other opening held in the outbox until quiet hours end, got %+v", entries)
+	}
+}
+
+func TestDispatcher_DoesNotPersistTransientEvents(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	push := &flakyNotifier{name: "ntfy", fail: true}
+	d := NewDispatcher([]Notifier{push}, AlertPolicy{})
+	d.outbox = openTestOutbox(t, &now)
+
+	d.Dispatch(Event{Kind: EventError, CRN: "12345", Message: "timeout"})
+	d.Dispatch(Event{Kind: EventSeatClosed, CRN: "12345"})
+
+	if entries := d.outbox.Entries(); len(entries) != 0 {
+		t.Errorf("expected errors and closings to skip the outbox, got %d entries", len(entries))
+	}
+}
+
+func TestRunOutboxCommand_ListAndPurge(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	o := openTestOutbox(t, &now)
+	pending, _ := o.Add("ntfy", Event{Kind: EventSeatOpen, CRN: "12345", Name: "CS-3114"})
+	o.Fail(pending, errors.New("timeout"))
+	failed, _ := o.Add("sms", Event{Kind: EventDigest})
+	for range outboxMaxAttempts {
+		o.Fail(failed, errors.New("invalid number"))
+	}
+
+	var out bytes.Buffer
+	if err := RunOutboxCommand([]string{"--file", o.path}, fakeEnv(nil), &out); err != nil {
+		t.Fatal(err)
+	}
+	listing := out.String()
+	for _, want := range []string{"pending", "failed", "seat_open", "last error: invalid number"} {
+		if !strings.Contains(listing, want) {
+			t.Errorf("listing missing %q:\n%s", want, listing)
+		}
+	}
+
+	out.Reset()
+	if err := RunOutboxCommand([]string{"purge", "--file", o.path, "--state", "failed"}, fakeEnv(nil), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Removed 1 entries") {
+		t.Errorf("unexpected purge output: %s", out.String())
+	}
+
+	reloaded, _ := OpenOutbox(o.path)
+	if entries := reloaded.Entries(); len(entries) != 1 || entries[0].Channel != "ntfy" {
+		t.Errorf("expected only the pending entry to remain, got %+v", entries)
+	}
+
+	if err := RunOutboxCommand([]string{"resend", "--file", o.path}, fakeEnv(nil), &out); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
+
+func TestRunOutboxCommand_UsesEachProfilesOutbox(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "retry.json")
+	path := writeConfigFile(t, "config.json", `{
+		"crns": ["12345"],
+		"ntfy": {"topic": "me"},
+		"outbox": "`+shared+`",
+		"profiles": [{"name": "alex", "crns": ["67890"], "ntfy": {"topic": "alex"}}]
+	}`)
+	for file, crn := range map[string]string{shared: "12345", filepath.Join(dir, "retry-alex.json"): "67890"} {
+		o, err := OpenOutbox(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o.Add("ntfy", Event{Kind: EventSeatOpen, CRN: crn, Name: "Course " + crn})
+	}
+
+	var out bytes.Buffer
+	env := fakeEnv(map[string]string{"OPENSEAT_CONFIG": path})
+	if err := RunOutboxCommand(nil, env, &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"default (" + shared + ")", "Course 12345", "alex (" + filepath.Join(dir, "retry-alex.json") + ")", "Course 67890"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("listing missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := RunOutboxCommand([]string{"purge"}, env, &out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(out.String(), "Removed 1 entries") != 2 {
+		t.Errorf("expected both outboxes purged:\n%s", out.String())
+	}
+}
+
+func TestRunOutboxCommand_DoesNotResolveSecrets(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	ran := filepath.Join(dir, "ran")
+	path := writeConfigFile(t, "config.json", `{
+		"crns": ["12345"],
+		"ntfy": {"topic": "me", "token": "cmd:touch `+ran+`"},
+		"telegram": {"token": "env:UNSET_BOT_TOKEN", "chatId": "1"},
+		"outbox": "`+filepath.Join(dir, "retry.json")+`"
+	}`)
+
+	var out bytes.Buffer
+	if err := RunOutboxCommand(nil, fakeEnv(map[string]string{"OPENSEAT_CONFIG": path}), &out); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ran); err == nil {
+		t.Error("listing the outbox shouldn't run cmd: secret references")
+	}
+	if !strings.Contains(out.String(), "Outbox is empty.") {
+		t.Errorf("unexpected listing:\n%s", out.String())
+	}
+}
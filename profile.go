@@ -0,0 +1,576 @@
+package main
+
+import (
+	"fmt"
+	"net/mail"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultProfile names the profile made from the top-level crns and channels
+const defaultProfile = "default"
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ProfileConfig is one person's watches and notification channels. Profiles
+// share a monitor, so a CRN several profiles watch is still checked once per cycle.
+type ProfileConfig struct {
+	Name     string          `json:"name"`     // Shown in the UI and used to name the profile's outbox file (required)
+	CRNs     WatchList       `json:"crns"`     // CRNs this profile watches, in the same forms as the top-level crns (required)
+	Email    string          `json:"email"`    // Email address for notifications (optional)
+	Ntfy     *NtfyConfig     `json:"ntfy"`     // ntfy push notifications (optional)
+	Gotify   *GotifyConfig   `json:"gotify"`   // Gotify push notifications (optional)
+	Telegram *TelegramConfig `json:"telegram"` // Telegram bot alerts and commands (optional)
+	SMS      *SMSConfig      `json:"sms"`      // Twilio text message alerts (optional)
+	Desktop  *DesktopConfig  `json:"desktop"`  // Desktop notifications and terminal signals (optional)
+	Exec     *ExecConfig     `json:"exec"`     // Script to run on monitor events (optional)
+}
+
+// validate reports problems with the profile under path, e.g. "profiles[1]"
+func (p ProfileConfig) validate(path string, errs *ConfigErrors) {
+	if !profileNamePattern.MatchString(p.Name) {
+		errs.add(path+".name", "%q is not a profile name; use lowercase letters, digits, - and _", p.Name)
+	}
+	if len(p.CRNs) == 0 {
+		errs.add(path+".crns", "no CRNs specified")
+	}
+	checkCRNs(p.CRNs.CRNs(), path+".crns", errs)
+	for i, w := range p.CRNs {
+		w.validate(fmt.Sprintf("%s.crns[%d]", path, i), errs)
+	}
+	if p.Email != "" {
+		if addr, err := mail.ParseAddress(p.Email); err != nil || addr.Address != p.Email {
+			errs.add(path+".email", "%q is not a valid email address", p.Email)
+		}
+	}
+	if p.SMS != nil {
+		checkCRNs(p.SMS.CRNs, path+".sms.crns", errs)
+	}
+}
+
+// profile is a profile's name and its settings as a complete config
+type profile struct {
+	Name   string
+	Config Config
+}
+
+// profiles returns the top-level watches and channels as the default profile
+// (unless profiles replace them entirely), followed by every configured
+// profile. Each profile's config is the shared config with its own CRNs and
+// channels, so escalation steps go through each profile's own channels.
+func (c Config) profiles() []profile {
+	var profiles []profile
+	if len(c.Profiles) == 0 || len(c.CRNs) > 0 || c.hasChannels() {
+		base := c
+		base.Profiles = nil
+		profiles = append(profiles, profile{Name: defaultProfile, Config: base})
+	}
+	for _, p := range c.Profiles {
+		pc := c
+		pc.Profiles = nil
+		pc.CRNs, pc.Email = p.CRNs, p.Email
+		pc.Ntfy, pc.Gotify, pc.Telegram, pc.SMS, pc.Desktop, pc.Exec = p.Ntfy, p.Gotify, p.Telegram, p.SMS, p.Desktop, p.Exec
+		pc.Outbox = profileOutboxPath(c.Outbox, p.Name)
+		profiles = append(profiles, profile{Name: p.Name, Config: pc})
+	}
+	return profiles
+}
+
+// hasChannels reports whether the top-level config sets up any notification channel
+func (c Config) hasChannels() bool {
+	return c.Email != "" || c.Ntfy != nil || c.Gotify != nil || c.Telegram != nil || c.SMS != nil || c.Desktop != nil || c.Exec != nil
+}
+
+// profileOutboxPath gives each profile its own outbox next to the shared one,
+// e.g. outbox-alice.json, so retries go out through the right profile's channels
+func profileOutboxPath(outbox, name string) string {
+	ext := filepath.Ext(outbox)
+	return strings.TrimSuffix(outbox, ext) + "-" + name + ext
+}
+
+// watchList merges the watches of every profile so each CRN is checked once.
+// When profiles share a CRN, the most eager settings win: the shortest
+// interval, the highest priority, the lowest seat threshold and the latest
+// expiry. Channels are left to each profile.
+func (c Config) watchList() WatchList {
+	if len(c.Profiles) == 0 {
+		return c.CRNs
+	}
+
+	var merged WatchList
+	for _, p := range c.profiles() {
+		for _, w := range p.Config.CRNs {
+			i := slices.IndexFunc(merged, func(m Watch) bool { return m.CRN == w.CRN })
+			if i < 0 {
+				w.Channels = nil
+				merged = append(merged, w)
+				continue
+			}
+			m := &merged[i]
+			if m.Label == "" {
+				m.Label = w.Label
+			}
+			if w.Interval > 0 && (m.Interval == 0 || w.Interval < m.Interval) {
+				m.Interval = w.Interval
+			}
+			if priorityRank(w.Priority) > priorityRank(m.Priority) {
+				m.Priority = w.Priority
+			}
+			m.MinSeats = min(m.minSeats(), w.minSeats())
+			if m.Expires != "" {
+				mEnd, _ := m.expiry()
+				wEnd, _ := w.expiry()
+				if w.Expires == "" || wEnd.After(mEnd) {
+					m.Expires = w.Expires
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// priorityRank orders priorities from least to most eager
+func priorityRank(p Priority) int {
+	switch p {
+	case PriorityLow:
+		return 0
+	case PriorityHigh:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// eventDispatcher is anything that accepts monitor events
+type eventDispatcher interface {
+	Dispatch(ev Event)
+}
+
+// ProfileDispatcher delivers one profile's notifications
+type ProfileDispatcher struct {
+	*Dispatcher
+	Name   string
+	Config Config
+
+	mu       sync.Mutex
+	added    map[string]bool // CRNs added with this profile's commands
+	resolved map[string]bool // CRNs this profile marked registered with /got
+	paused   bool            // alerts paused with /pause
+}
+
+// subscribed reports whether the profile wants events about crn
+func (p *ProfileDispatcher) subscribed(crn string, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.added[crn] {
+		return true
+	}
+	i := slices.IndexFunc(p.Config.CRNs, func(w Watch) bool { return w.CRN == crn })
+	return i >= 0 && !p.Config.CRNs[i].expired(now)
+}
+
+// receives reports whether the profile currently wants alerts about crn: it
+// isn't paused and hasn't marked the CRN registered
+func (p *ProfileDispatcher) receives(crn string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.paused && !p.resolved[crn]
+}
+
+// hasResolved reports whether the profile marked crn registered
+func (p *ProfileDispatcher) hasResolved(crn string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.resolved[crn]
+}
+
+// Fanout sends each event to the profiles it concerns: events about a CRN go to
+// the profiles watching it, and everything else (digests, health alerts, CRNs
+// added from the terminal) goes to every profile
+type Fanout struct {
+	// Profiles is replaced by Reconfigure while command listeners read it, so
+	// after NewFanout it is only read through profiles()
+	Profiles []*ProfileDispatcher
+	mu       sync.Mutex
+
+	emailSender EmailSender
+	templates   *Templates
+	quotas      *QuotaTracker
+	courses     func() []CourseStatus
+	now         func() time.Time
+}
+
+// NewFanout builds a dispatcher for every profile in cfg. Templates and
+// quotas are shared; each profile has its own channels and outbox.
+func NewFanout(cfg Config, emailSender EmailSender) (*Fanout, error) {
+	f := &Fanout{emailSender: emailSender, now: time.Now}
+	var err error
+	if f.templates, err = LoadTemplates(cfg.Templates); err != nil {
+		return nil, err
+	}
+	if cfg.Quotas != nil {
+		if f.quotas, err = OpenQuotaTracker(*cfg.Quotas); err != nil {
+			return nil, err
+		}
+		f.quotas.Report(time.Now())
+	}
+	for _, p := range cfg.profiles() {
+		pd, err := f.newProfile(p)
+		if err != nil {
+			return nil, err
+		}
+		f.Profiles = append(f.Profiles, pd)
+	}
+	return f, nil
+}
+
+// newProfile creates the dispatcher for one profile
+func (f *Fanout) newProfile(p profile) (*ProfileDispatcher, error) {
+	d := NewDispatcher(buildNotifiers(p.Config, f.emailSender), p.Config.Alerts)
+	outbox, err := OpenOutbox(p.Config.Outbox)
+	if err != nil {
+		return nil, err
+	}
+	d.outbox = outbox
+	d.quotas = f.quotas
+	d.profile = p.Name
+	d.configure(func(s *dispatchSettings) {
+		s.quiet = p.Config.QuietHours
+		s.templates = f.templates
+		s.channels = p.Config.CRNs.channels()
+	})
+	if p.Config.Escalation != nil {
+		d.escalator = NewEscalator(*p.Config.Escalation)
+		d.escalator.setPriorities(p.Config.CRNs.priorities())
+	}
+	pd := &ProfileDispatcher{Dispatcher: d, Name: p.Name, Config: p.Config, added: map[string]bool{}, resolved: map[string]bool{}}
+	d.courses = func() []CourseStatus {
+		if f.courses == nil {
+			return nil
+		}
+		return f.visible(pd, f.courses())
+	}
+	return pd, nil
+}
+
+// Reconfigure switches to a reloaded config. Profiles that still exist keep
+// their outbox and any channel whose settings didn't change; new profiles are
+// created and removed ones dropped.
+func (f *Fanout) Reconfigure(next Config, templates *Templates) error {
+	f.templates = templates
+	current := f.profiles()
+	var profiles []*ProfileDispatcher
+	for _, p := range next.profiles() {
+		i := slices.IndexFunc(current, func(pd *ProfileDispatcher) bool { return pd.Name == p.Name })
+		if i < 0 {
+			pd, err := f.newProfile(p)
+			if err != nil {
+				return err
+			}
+			profiles = append(profiles, pd)
+			continue
+		}
+
+		pd := current[i]
+		d := pd.Dispatcher
+		pd.mu.Lock()
+		old := pd.Config
+		pd.Config = p.Config
+		pd.mu.Unlock()
+		d.configure(func(s *dispatchSettings) {
+			s.notifiers = reuseNotifiers(s.notifiers, buildNotifiers(p.Config, f.emailSender), old, p.Config)
+			s.policy = p.Config.Alerts
+			s.quiet = p.Config.QuietHours
+			s.templates = templates
+			s.channels = p.Config.CRNs.channels()
+		})
+		if d.escalator != nil {
+			d.escalator.setPriorities(p.Config.CRNs.priorities())
+		}
+		profiles = append(profiles, pd)
+	}
+	f.mu.Lock()
+	f.Profiles = profiles
+	f.mu.Unlock()
+	return nil
+}
+
+// profiles returns the current profiles
+func (f *Fanout) profiles() []*ProfileDispatcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Profiles
+}
+
+// SetCourses gives templates and command replies access to the watch list
+func (f *Fanout) SetCourses(courses func() []CourseStatus) {
+	f.courses = courses
+}
+
+// Dispatch sends the event to every profile it concerns
+func (f *Fanout) Dispatch(ev Event) {
+	for _, p := range f.recipients(ev.CRN) {
+		p.Dispatch(ev)
+	}
+}
+
+// recipients returns the profiles that want events about crn: subscribers
+// that haven't paused alerts or marked the CRN registered. Events without a
+// CRN go to every profile.
+func (f *Fanout) recipients(crn string) []*ProfileDispatcher {
+	if crn == "" {
+		return f.profiles()
+	}
+	var receiving []*ProfileDispatcher
+	for _, p := range f.subscribers(crn) {
+		if p.receives(crn) {
+			receiving = append(receiving, p)
+		}
+	}
+	return receiving
+}
+
+// subscribers returns the profiles watching crn, or every profile when nobody watches it
+func (f *Fanout) subscribers(crn string) []*ProfileDispatcher {
+	now := f.now()
+	var subscribed []*ProfileDispatcher
+	for _, p := range f.profiles() {
+		if p.subscribed(crn, now) {
+			subscribed = append(subscribed, p)
+		}
+	}
+	if len(subscribed) == 0 {
+		return f.profiles()
+	}
+	return subscribed
+}
+
+// Wanted reports whether any profile still wants crn checked. A CRN whose
+// subscribers have all paused or marked it registered is skipped.
+func (f *Fanout) Wanted(crn string) bool {
+	return len(f.recipients(crn)) > 0
+}
+
+// visible filters courses to those a profile sees: its own and those nobody
+// watches. CRNs the profile marked registered are shown as found.
+func (f *Fanout) visible(p *ProfileDispatcher, courses []CourseStatus) []CourseStatus {
+	var shown []CourseStatus
+	for _, c := range courses {
+		if !slices.Contains(f.subscribers(c.CRN), p) {
+			continue
+		}
+		c.Found = c.Found || p.hasResolved(c.CRN)
+		shown = append(shown, c)
+	}
+	return shown
+}
+
+// Flush sends every profile's collected openings
+func (f *Fanout) Flush() {
+	for _, p := range f.profiles() {
+		p.Flush()
+	}
+}
+
+// Wait blocks until every profile's background deliveries have finished
+func (f *Fanout) Wait() {
+	for _, p := range f.profiles() {
+		p.Wait()
+	}
+}
+
+// RetryDue retries every profile's failed deliveries that are due
+func (f *Fanout) RetryDue() {
+	for _, p := range f.profiles() {
+		p.RetryDue()
+	}
+}
+
+// Escalate sends escalation steps that have come due
+func (f *Fanout) Escalate() {
+	for _, p := range f.profiles() {
+		p.Escalate()
+	}
+}
+
+// Escalating reports whether any profile has an opening waiting for acknowledgement
+func (f *Fanout) Escalating() bool {
+	return slices.ContainsFunc(f.profiles(), func(p *ProfileDispatcher) bool { return p.Escalating() })
+}
+
+// ReportStatus passes the found/total counts to channels that display them
+func (f *Fanout) ReportStatus(found, total int) {
+	for _, p := range f.profiles() {
+		p.ReportStatus(found, total)
+	}
+}
+
+// escalators returns every profile's escalator
+func (f *Fanout) escalators() []*Escalator {
+	var escalators []*Escalator
+	for _, p := range f.profiles() {
+		if p.escalator != nil {
+			escalators = append(escalators, p.escalator)
+		}
+	}
+	return escalators
+}
+
+// Acknowledge stops escalating alerts for crn ("" for every alert) in every
+// profile and returns how many were stopped
+func (f *Fanout) Acknowledge(crn string) int {
+	stopped := 0
+	for _, e := range f.escalators() {
+		stopped += e.Acknowledge(crn)
+	}
+	return stopped
+}
+
+// ServeAcks runs one acknowledgement server for every profile's escalation
+// links until stop is closed. It returns at once if nothing escalates.
+func (f *Fanout) ServeAcks(stop <-chan struct{}) error {
+	escalators := f.escalators()
+	if len(escalators) == 0 {
+		return nil
+	}
+	return serveAcks(escalators[0].listenAddr(), ackHandler(f.escalators), stop)
+}
+
+// Listeners returns every channel that accepts commands, each paired with a
+// control scoped to its profile
+func (f *Fanout) Listeners(monitor *Monitor) map[commandListener]MonitorControl {
+	targets := map[commandListener]MonitorControl{}
+	for _, p := range f.profiles() {
+		for _, n := range p.current().notifiers {
+			if l, ok := n.(commandListener); ok {
+				targets[l] = profileControl{Monitor: monitor, fanout: f, profile: p}
+			}
+		}
+	}
+	return targets
+}
+
+// profileControl scopes remote commands to one profile: /status lists the
+// profile's CRNs, /add subscribes the profile and /remove unsubscribes it,
+// leaving CRNs other profiles still watch in the monitor. /got, /missed,
+// /pause and /ack only change the profile's own alerts; the monitor stops
+// checking a CRN once no profile watching it wants it any more.
+type profileControl struct {
+	*Monitor
+	fanout  *Fanout
+	profile *ProfileDispatcher
+}
+
+func (c profileControl) Status() []CourseStatus {
+	return c.fanout.visible(c.profile, c.Monitor.Status())
+}
+
+// Resolve records the profile's registration outcome. "got" stops its alerts
+// for the CRN, and stops checking it once every profile watching it has got
+// it; "missed" re-arms the profile and restarts checking if it had stopped.
+func (c profileControl) Resolve(crn string, registered bool) error {
+	p := c.profile
+	courses := c.Monitor.Status()
+	i := slices.IndexFunc(courses, func(course CourseStatus) bool { return course.CRN == crn })
+	if i < 0 || !slices.Contains(c.fanout.subscribers(crn), p) {
+		return fmt.Errorf("CRN %s is not being monitored", crn)
+	}
+
+	p.mu.Lock()
+	if registered {
+		p.resolved[crn] = true
+	} else {
+		delete(p.resolved, crn)
+	}
+	p.mu.Unlock()
+	if p.escalator != nil {
+		p.escalator.Acknowledge(crn)
+	}
+
+	if registered {
+		if slices.ContainsFunc(c.fanout.subscribers(crn), func(other *ProfileDispatcher) bool { return !other.hasResolved(crn) }) {
+			return nil // still wanted by another profile
+		}
+		return c.Monitor.resolve(crn, true)
+	}
+	// re-arming reports a current opening again, which only this profile should hear
+	alone := !slices.ContainsFunc(c.fanout.subscribers(crn), func(other *ProfileDispatcher) bool { return other != p && other.receives(crn) })
+	if courses[i].Found || alone {
+		return c.Monitor.resolve(crn, false)
+	}
+	return nil
+}
+
+// SetPaused pauses or resumes the profile's alerts. Polling pauses once every
+// profile has paused.
+func (c profileControl) SetPaused(paused bool) {
+	c.profile.mu.Lock()
+	c.profile.paused = paused
+	c.profile.mu.Unlock()
+	c.Monitor.SetPaused(!slices.ContainsFunc(c.fanout.profiles(), func(p *ProfileDispatcher) bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return !p.paused
+	}))
+}
+
+func (c profileControl) Paused() bool {
+	c.profile.mu.Lock()
+	defer c.profile.mu.Unlock()
+	return c.profile.paused
+}
+
+// Acknowledge stops only the profile's escalating alerts
+func (c profileControl) Acknowledge(crn string) int {
+	if c.profile.escalator == nil {
+		return 0
+	}
+	return c.profile.escalator.Acknowledge(crn)
+}
+
+func (c profileControl) AddCRN(crn string) (CourseStatus, error) {
+	if c.profile.subscribed(crn, c.fanout.now()) {
+		return CourseStatus{}, fmt.Errorf("CRN %s is already being monitored", crn)
+	}
+	// a CRN another profile watches is already being checked
+	for _, course := range c.Monitor.Status() {
+		if course.CRN == crn {
+			c.subscribe(crn)
+			return course, nil
+		}
+	}
+	course, err := c.Monitor.AddCRN(crn)
+	if err != nil {
+		return CourseStatus{}, err
+	}
+	c.subscribe(crn)
+	return course, nil
+}
+
+func (c profileControl) RemoveCRN(crn string) bool {
+	p := c.profile
+	subscribed := p.subscribed(crn, c.fanout.now())
+	if subscribed {
+		p.mu.Lock()
+		delete(p.added, crn)
+		p.Config.CRNs = slices.DeleteFunc(slices.Clone(p.Config.CRNs), func(w Watch) bool { return w.CRN == crn })
+		p.mu.Unlock()
+	}
+
+	for _, other := range c.fanout.profiles() {
+		if other != p && other.subscribed(crn, c.fanout.now()) {
+			return subscribed // still watched by someone else
+		}
+	}
+	return c.Monitor.RemoveCRN(crn) || subscribed
+}
+
+func (c profileControl) subscribe(crn string) {
+	c.profile.mu.Lock()
+	defer c.profile.mu.Unlock()
+	c.profile.added[crn] = true
+}
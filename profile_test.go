@@ -0,0 +1,346 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// Profile tests
+// ===================
+
+func TestLoadConfig_Profiles(t *testing.T) {
+	path := createTempConfig(t, `{
+		"crns": ["12345"],
+		"ntfy": {"topic": "me"},
+		"profiles": [
+			{"name": "alex", "crns": ["12345", {"crn": "67890", "priority": "high"}], "ntfy": {"topic": "alex"}}
+		]
+	}`)
+
+	cfg, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiles := cfg.profiles()
+	if len(profiles) != 2 || profiles[0].Name != defaultProfile || profiles[1].Name != "alex" {
+		t.Fatalf("unexpected profiles: %+v", profiles)
+	}
+	alex := profiles[1].Config
+	if alex.Ntfy.Topic != "alex" || alex.CRNs.Get("67890").Priority != PriorityHigh {
+		t.Errorf("profile settings not applied: %+v", alex)
+	}
+	if alex.Outbox != "outbox-alex.json" {
+		t.Errorf("profile should have its own outbox, got %q", alex.Outbox)
+	}
+	if got := cfg.watchList().CRNs(); len(got) != 2 {
+		t.Errorf("shared CRN should be polled once, got %v", got)
+	}
+}
+
+func TestLoadConfig_ProfileProblemsHavePaths(t *testing.T) {
+	path := createTempConfig(t, `{
+		"crns": ["12345"],
+		"profiles": [
+			{"name": "Alex", "crns": ["1234"]},
+			{"name": "sam", "crns": [], "email": "nope"},
+			{"name": "sam", "crns": ["12345"], "ntfy": {"topik": "x"}},
+			{"name": "default", "crns": ["12345"]}
+		]
+	}`)
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		`profiles[0].name: "Alex" is not a profile name`,
+		`profiles[0].crns[0]: "1234" is not a CRN`,
+		`profiles[1].crns: no CRNs specified`,
+		`profiles[1].email: "nope" is not a valid email address`,
+		`profiles[2].name: "sam" is already used by profiles[1]`,
+		`profiles[2].ntfy.topik: unknown field`,
+		`profiles[3].name: "default" is reserved`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+
+	// profiles alone are enough
+	path = createTempConfig(t, `{"profiles": [{"name": "alex", "crns": ["12345"]}]}`)
+	cfg, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profiles := cfg.profiles(); len(profiles) != 1 || profiles[0].Name != "alex" {
+		t.Errorf("without top-level crns or channels there should be no default profile: %+v", profiles)
+	}
+}
+
+func TestConfig_WatchListMergesSharedCRNs(t *testing.T) {
+	cfg := Config{
+		CRNs: WatchList{{CRN: "12345", Priority: PriorityLow, MinSeats: 3, Expires: "2026-01-10", Channels: []string{"sms"}}},
+		Profiles: []ProfileConfig{
+			{Name: "alex", CRNs: WatchList{{CRN: "12345", Label: "Lab", Interval: 20, Priority: PriorityHigh, Expires: "2026-02-01"}}},
+			{Name: "sam", CRNs: WatchList{{CRN: "12345", Interval: 60, MinSeats: 2}}},
+		},
+	}
+
+	w := cfg.watchList().Get("12345")
+	if w.Label != "Lab" || w.Interval != 20 || w.Priority != PriorityHigh || w.minSeats() != 1 || w.Expires != "" {
+		t.Errorf("expected the most eager settings, got %+v", w)
+	}
+	if len(w.Channels) != 0 {
+		t.Errorf("channels belong to each profile, got %v", w.Channels)
+	}
+}
+
+// newTestFanout builds a fanout whose profiles use counting notifiers
+func newTestFanout(t *testing.T, cfg Config) (*Fanout, map[string]*countingNotifier) {
+	t.Helper()
+	cfg.Outbox = filepath.Join(t.TempDir(), "outbox.json")
+	fanout, err := NewFanout(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sinks := map[string]*countingNotifier{}
+	for _, p := range fanout.Profiles {
+		sinks[p.Name] = &countingNotifier{name: "ntfy"}
+		p.configure(func(s *dispatchSettings) { s.notifiers = []Notifier{sinks[p.Name]} })
+	}
+	return fanout, sinks
+}
+
+func TestFanout_RoutesEventsToSubscribedProfiles(t *testing.T) {
+	fanout, sinks := newTestFanout(t, Config{
+		CRNs: newWatchList("12345", "67890"),
+		Profiles: []ProfileConfig{
+			{Name: "alex", CRNs: WatchList{{CRN: "67890"}, {CRN: "11111", Expires: "2026-01-01"}}},
+		},
+	})
+	fanout.now = func() time.Time { return time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local) }
+
+	fanout.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	fanout.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	fanout.Dispatch(Event{Kind: EventSeatOpen, CRN: "11111"}) // alex's watch has expired, so nobody watches it
+	fanout.Dispatch(Event{Kind: EventDigest})
+	fanout.Flush()
+
+	me, alex := sinks[defaultProfile], sinks["alex"]
+	// the digest goes out immediately, openings when they're flushed
+	if len(me.events) != 2 || len(me.events[1].CRNs()) != 3 {
+		t.Errorf("default profile should hear about all three openings and the digest, got %+v", me.events)
+	}
+	if len(alex.events) != 2 || len(alex.events[1].CRNs()) != 2 {
+		t.Errorf("alex should hear about 67890, 11111 and the digest, got %+v", alex.events)
+	}
+}
+
+func TestProfileControl_AddRemove(t *testing.T) {
+	fanout, _ := newTestFanout(t, Config{
+		CRNs:     newWatchList("12345"),
+		Profiles: []ProfileConfig{{Name: "alex", CRNs: newWatchList("12345")}},
+	})
+	cfg := Config{CRNs: newWatchList("12345")}
+	monitor := NewMonitor(cfg, []CourseStatus{{CRN: "12345", Name: "Data Structures"}, {CRN: "67890", Name: "Calculus"}})
+	me, alex := profileControl{monitor, fanout, fanout.Profiles[0]}, profileControl{monitor, fanout, fanout.Profiles[1]}
+
+	// alex subscribes to a CRN that is already polled, without a timetable lookup
+	if course, err := alex.AddCRN("67890"); err != nil || course.Name != "Calculus" {
+		t.Fatalf("AddCRN = %+v, %v", course, err)
+	}
+	if _, err := alex.AddCRN("67890"); err == nil {
+		t.Error("expected an error adding the same CRN twice")
+	}
+	if got := len(alex.Status()); got != 2 {
+		t.Errorf("alex should see 2 courses, got %d", got)
+	}
+
+	// removing a shared CRN only unsubscribes
+	if !alex.RemoveCRN("12345") {
+		t.Fatal("expected alex to unsubscribe from 12345")
+	}
+	if len(monitor.Status()) != 2 {
+		t.Error("12345 is still watched by the default profile and should stay in the monitor")
+	}
+	if me.RemoveCRN("67890") {
+		t.Error("the default profile shouldn't be able to remove alex's CRN")
+	}
+	if !me.RemoveCRN("12345") || len(monitor.Status()) != 1 {
+		t.Errorf("removing the last subscriber should remove the CRN, got %+v", monitor.Status())
+	}
+}
+
+func TestFanout_EscalatesPerProfile(t *testing.T) {
+	fanout, _ := newTestFanout(t, Config{
+		CRNs:       newWatchList("12345"),
+		Escalation: &EscalationConfig{Steps: []EscalationStep{{Channel: "sms", After: 60}}},
+		Profiles:   []ProfileConfig{{Name: "alex", CRNs: newWatchList("12345")}},
+	})
+	me, alex := fanout.Profiles[0], fanout.Profiles[1]
+	if me.escalator == nil || alex.escalator == nil {
+		t.Fatal("expected escalation in every profile")
+	}
+
+	fanout.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	if !me.Escalating() || !alex.Escalating() {
+		t.Fatal("expected the opening to escalate in both profiles")
+	}
+
+	// one server acknowledges any profile's links, and only that profile's alert
+	ev := alex.escalator.Start(Event{Kind: EventSeatOpen, CRN: "12345"})
+	id := ev.AckURL[strings.LastIndex(ev.AckURL, "/")+1:]
+	server := httptest.NewServer(ackHandler(fanout.escalators))
+	defer server.Close()
+	resp, err := http.Post(server.URL+"/ack/"+id, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || alex.Escalating() || !me.Escalating() {
+		t.Errorf("status %d; expected only alex's alert acknowledged", resp.StatusCode)
+	}
+
+	// the terminal acknowledges every profile's alerts
+	if n := fanout.Acknowledge("12345"); n != 1 || me.Escalating() {
+		t.Errorf("Acknowledge stopped %d alerts, want 1", n)
+	}
+}
+
+func TestProfileControl_CommandsOnlyAffectTheProfile(t *testing.T) {
+	fanout, sinks := newTestFanout(t, Config{
+		CRNs:       newWatchList("12345", "67890"),
+		Escalation: &EscalationConfig{Steps: []EscalationStep{{Channel: "sms", After: 60}}},
+		Profiles:   []ProfileConfig{{Name: "alex", CRNs: newWatchList("12345")}},
+	})
+	monitor := NewMonitor(Config{}, []CourseStatus{{CRN: "12345", Name: "Data Structures"}, {CRN: "67890", Name: "Calculus"}})
+	monitor.escalator = fanout
+	me, alex := profileControl{monitor, fanout, fanout.Profiles[0]}, profileControl{monitor, fanout, fanout.Profiles[1]}
+
+	// alex registering for a shared CRN stops only alex's alerts and escalation
+	fanout.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	if reply := handleCommand("/got 12345", alex); !strings.Contains(reply, "Stopped monitoring") {
+		t.Fatalf("/got reply = %q", reply)
+	}
+	if alex.profile.Escalating() || !me.profile.Escalating() {
+		t.Error("/got should only stop alex's escalation")
+	}
+	if monitor.Status()[0].Found || !fanout.Wanted("12345") {
+		t.Error("12345 is still wanted by the default profile and should keep being checked")
+	}
+	if handleCommand("/ack", alex); !me.profile.Escalating() {
+		t.Error("alex's /ack should not stop the default profile's escalation")
+	}
+
+	fanout.Dispatch(Event{Kind: EventSeatClosed, CRN: "12345"})
+	if len(sinks["alex"].events) != 0 || len(sinks[defaultProfile].events) != 1 {
+		t.Errorf("only the default profile should hear about 12345 now, alex got %+v", sinks["alex"].events)
+	}
+
+	// once everyone watching it has it, the CRN stops being checked
+	handleCommand("/got 12345", me)
+	if !monitor.Status()[0].Found {
+		t.Error("expected 12345 found once every subscriber got it")
+	}
+
+	// pausing one profile keeps polling for the others
+	handleCommand("/pause", alex)
+	if monitor.Paused() || !alex.Paused() || me.Paused() {
+		t.Error("alex's /pause should only pause alex")
+	}
+	handleCommand("/pause", me)
+	if !monitor.Paused() {
+		t.Error("expected polling paused once every profile paused")
+	}
+	handleCommand("/resume", alex)
+	if monitor.Paused() {
+		t.Error("expected polling resumed for alex")
+	}
+	if fanout.Wanted("67890") {
+		t.Error("67890 is only watched by the paused default profile and shouldn't be checked")
+	}
+}
+
+func TestFanout_ReconfigureWhileListening(t *testing.T) {
+	cfg := Config{CRNs: newWatchList("12345"), Profiles: []ProfileConfig{{Name: "alex", CRNs: newWatchList("12345")}}}
+	fanout, _ := newTestFanout(t, cfg)
+	monitor := NewMonitor(cfg, []CourseStatus{{CRN: "12345", Name: "Data Structures"}})
+	alex := profileControl{monitor, fanout, fanout.Profiles[1]}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			handleCommand("/status", alex)
+			handleCommand("/pause", alex)
+		}
+	}()
+	for range 100 {
+		if err := fanout.Reconfigure(fanout.Profiles[0].Config, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
+
+// slowHook is a background notifier that waits for release before returning
+type slowHook struct {
+	countingNotifier
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *slowHook) PrefersUrgent() bool    { return true }
+func (h *slowHook) RunsInBackground() bool { return true }
+
+func (h *slowHook) Notify(ev Event) error {
+	close(h.started)
+	<-h.release
+	return h.countingNotifier.Notify(ev)
+}
+
+func TestFanout_ReconfigureDuringBackgroundDelivery(t *testing.T) {
+	cfg := Config{CRNs: newWatchList("12345")}
+	fanout, _ := newTestFanout(t, cfg)
+	hook := &slowHook{countingNotifier: countingNotifier{name: "exec"}, started: make(chan struct{}), release: make(chan struct{})}
+	fanout.Profiles[0].configure(func(s *dispatchSettings) { s.notifiers = append(s.notifiers, hook) })
+
+	fanout.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	<-hook.started
+	next := cfg
+	next.Alerts.Cooldown = 60
+	next.QuietHours = &QuietHoursConfig{Channels: map[string]QuietWindow{"ntfy": {Start: "22:00", End: "07:00"}}}
+	if err := fanout.Reconfigure(next, nil); err != nil {
+		t.Fatal(err)
+	}
+	close(hook.release)
+	fanout.Wait()
+
+	if hook.sent != 1 {
+		t.Errorf("the delivery in flight should finish after the reload, got %d sends", hook.sent)
+	}
+}
+
+func TestConfigValidate_ProfilesNeedTheirOwnBot(t *testing.T) {
+	cfg := Config{
+		Telegram: &TelegramConfig{Token: "123:abc", ChatID: "1", Commands: true},
+		Profiles: []ProfileConfig{
+			{Name: "alex", CRNs: newWatchList("12345"), Telegram: &TelegramConfig{Token: "123:abc", ChatID: "2", Commands: true}},
+			{Name: "sam", CRNs: newWatchList("12345"), Telegram: &TelegramConfig{Token: "123:abc", ChatID: "3"}},
+		},
+	}
+	var errs ConfigErrors
+	for _, fe := range cfg.validate() {
+		if strings.Contains(fe.Path, "telegram") {
+			errs = append(errs, fe)
+		}
+	}
+	if len(errs) != 1 || errs[0].Path != "profiles[0].telegram.token" || !strings.Contains(errs[0].Message, "same bot as telegram") {
+		t.Errorf("expected only alex's listening bot rejected, got %v", errs)
+	}
+}
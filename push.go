@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ===================================
+// ntfy
+// ===================================
+
+// DefaultNtfyServer is the public ntfy instance used when no server is configured
+const DefaultNtfyServer = "https://ntfy.sh"
+
+// ntfy priorities range from 1 (min) to 5 (max)
+const (
+	ntfyPriorityLow = 2
+	ntfyPriorityMax = 5
+)
+
+// NtfyConfig configures push notifications through an ntfy server
+type NtfyConfig struct {
+	Server   string   `json:"server"`   // Server base URL (defaults to https://ntfy.sh)
+	Topic    string   `json:"topic"`    // Topic to publish to (required)
+	Token    Secret   `json:"token"`    // Access token for protected topics (optional)
+	Priority int      `json:"priority"` // Priority for events without a fixed mapping (1-5, default 3)
+	Tags     []string `json:"tags"`     // Extra tags/emoji shortcodes added to every message
+
+	ErrorThreshold int `json:"errorThreshold"` // Consecutive failures before an error is pushed (default 3)
+}
+
+// NtfyNotifier publishes events to an ntfy topic
+type NtfyNotifier struct {
+	Config NtfyConfig
+}
+
+func (n *NtfyNotifier) Name() string { return "ntfy" }
+
+// Accepts pushes a check error once per failure streak, when it reaches the threshold
+func (n *NtfyNotifier) Accepts(ev Event) bool {
+	return ev.Kind != EventError || reachesErrorThreshold(ev, n.Config.ErrorThreshold)
+}
+
+// priority maps an event to an ntfy priority: seat openings and monitor outages are max, errors are low
+func (n *NtfyNotifier) priority(kind EventKind) int {
+	switch kind {
+	case EventSeatOpen, EventUnhealthy:
+		return ntfyPriorityMax
+	case EventError:
+		return ntfyPriorityLow
+	}
+	if n.Config.Priority != 0 {
+		return n.Config.Priority
+	}
+	return 3
+}
+
+func (n *NtfyNotifier) Notify(ev Event) error {
+	if n.Config.Topic == "" {
+		return fmt.Errorf("ntfy topic not set")
+	}
+	server := n.Config.Server
+	if server == "" {
+		server = DefaultNtfyServer
+	}
+	target := strings.TrimRight(server, "/") + "/" + n.Config.Topic
+
+	req, err := http.NewRequest(http.MethodPost, target, strings.NewReader(ev.Body()))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Title", ev.Title())
+	req.Header.Set("Priority", strconv.Itoa(n.priority(ev.Kind)))
+
+	tags := append([]string{}, n.Config.Tags...)
+	switch ev.Kind {
+	case EventSeatOpen:
+		tags = append(tags, "tada")
+		req.Header.Set("Click", RegistrationURL)
+	case EventError:
+		tags = append(tags, "warning")
+	}
+	if len(tags) > 0 {
+		req.Header.Set("Tags", strings.Join(tags, ","))
+	}
+	if n.Config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Config.Token.Value())
+	}
+
+	return doNotifyRequest(req)
+}
+
+// ===================================
+// Gotify
+// ===================================
+
+// Gotify priorities range from 0 to 10; clients treat 8+ as high
+const (
+	gotifyPriorityLow = 2
+	gotifyPriorityMax = 10
+)
+
+// GotifyConfig configures push notifications through a Gotify server
+type GotifyConfig struct {
+	Server   string `json:"server"`   // Server base URL (required)
+	Token    Secret `json:"token"`    // Application token (required)
+	Priority int    `json:"priority"` // Priority for events without a fixed mapping (0-10, default 5)
+
+	ErrorThreshold int `json:"errorThreshold"` // Consecutive failures before an error is pushed (default 3)
+}
+
+// GotifyNotifier posts events as Gotify application messages
+type GotifyNotifier struct {
+	Config GotifyConfig
+}
+
+func (n *GotifyNotifier) Name() string { return "gotify" }
+
+// Accepts pushes a check error once per failure streak, when it reaches the threshold
+func (n *GotifyNotifier) Accepts(ev Event) bool {
+	return ev.Kind != EventError || reachesErrorThreshold(ev, n.Config.ErrorThreshold)
+}
+
+// priority maps an event to a Gotify priority: seat openings and monitor outages are max, errors are low
+func (n *GotifyNotifier) priority(kind EventKind) int {
+	switch kind {
+	case EventSeatOpen, EventUnhealthy:
+		return gotifyPriorityMax
+	case EventError:
+		return gotifyPriorityLow
+	}
+	if n.Config.Priority != 0 {
+		return n.Config.Priority
+	}
+	return 5
+}
+
+func (n *GotifyNotifier) Notify(ev Event) error {
+	if n.Config.Server == "" || n.Config.Token == "" {
+		return fmt.Errorf("gotify server and token must be set")
+	}
+
+	msg := map[string]any{
+		"title":    ev.Title(),
+		"message":  ev.Body(),
+		"priority": n.priority(ev.Kind),
+	}
+	if ev.Kind == EventSeatOpen {
+		msg["extras"] = map[string]any{
+			"client::notification": map[string]any{
+				"click": map[string]string{"url": RegistrationURL},
+			},
+		}
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	target := strings.TrimRight(n.Config.Server, "/") + "/message"
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", n.Config.Token.Value())
+
+	return doNotifyRequest(req)
+}
+
+// doNotifyRequest sends a notification request and treats any non-2xx status as an error
+func doNotifyRequest(req *http.Request) error {
+	resp, err := notifyClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %s", errRateLimited, resp.Status)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// QuietHoursConfig silences channels during parts of the day, e.g. no SMS
+// overnight while email keeps arriving
+type QuietHoursConfig struct {
+	Timezone string                 `json:"timezone"` // IANA time zone the windows are in, e.g. "America/New_York" (default local)
+	Channels map[string]QuietWindow `json:"channels"` // Quiet window per channel name
+	Critical []string               `json:"critical"` // CRNs that alert immediately even during quiet hours
+}
+
+// QuietWindow is a daily period when a channel should stay silent
+type QuietWindow struct {
+	Start    string `json:"start"`    // Start of the quiet period, HH:MM (required)
+	End      string `json:"end"`      // End of the quiet period, HH:MM; may be earlier than start to wrap past midnight (required)
+	Action   string `json:"action"`   // What to do with seat openings: "hold" until the window ends (default) or "downgrade"
+	Fallback string `json:"fallback"` // Channel that receives downgraded openings instead (required for "downgrade")
+}
+
+// quietDecision is what happens to an event bound for a channel
+type quietDecision int
+
+const (
+	quietSend      quietDecision = iota // deliver normally
+	quietHold                           // keep until the quiet window ends
+	quietDowngrade                      // send through the fallback channel instead
+	quietSkip                           // drop; only seat openings are held or rerouted
+)
+
+// validate reports problems with the time zone, critical CRNs and every
+// channel's window under path, e.g. "quietHours"
+func (q QuietHoursConfig) validate(path string, errs *ConfigErrors) {
+	if _, err := q.location(); err != nil {
+		errs.add(path+".timezone", "%q is not a time zone; use an IANA name such as America/New_York", q.Timezone)
+	}
+	checkCRNs(q.Critical, path+".critical", errs)
+	for _, channel := range sortedKeys(q.Channels) {
+		w, wPath := q.Channels[channel], joinPath(path+".channels", channel)
+		checkChannel(channel, wPath, errs)
+		if _, err := parseClock(w.Start); err != nil {
+			errs.add(wPath+".start", "must be HH:MM, got %q", w.Start)
+		}
+		if _, err := parseClock(w.End); err != nil {
+			errs.add(wPath+".end", "must be HH:MM, got %q", w.End)
+		}
+		switch w.Action {
+		case "", "hold":
+		case "downgrade":
+			if w.Fallback == "" || w.Fallback == channel {
+				errs.add(wPath+".fallback", "downgrade needs a different fallback channel")
+			}
+		default:
+			errs.add(wPath+".action", "must be \"hold\" or \"downgrade\", got %q", w.Action)
+		}
+		if w.Fallback != "" && w.Fallback != channel {
+			checkChannel(w.Fallback, wPath+".fallback", errs)
+		}
+	}
+}
+
+// location returns the configured time zone, or the machine's local zone
+func (q QuietHoursConfig) location() (*time.Location, error) {
+	if q.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("quietHours timezone %q: %w", q.Timezone, err)
+	}
+	return loc, nil
+}
+
+// parseClock converts HH:MM to minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// active reports whether the channel is inside its quiet window at the given time
+func (q QuietHoursConfig) active(channel string, now time.Time) bool {
+	w, ok := q.Channels[channel]
+	if !ok {
+		return false
+	}
+	loc, err := q.location()
+	if err != nil {
+		return false
+	}
+	start, errStart := parseClock(w.Start)
+	end, errEnd := parseClock(w.End)
+	if errStart != nil || errEnd != nil || start == end {
+		return false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end // wraps past midnight
+}
+
+// ends returns when the channel's quiet window that is active at now ends
+func (q QuietHoursConfig) ends(channel string, now time.Time) time.Time {
+	loc, err := q.location()
+	if err != nil {
+		return now
+	}
+	end, err := parseClock(q.Channels[channel].End)
+	if err != nil {
+		return now
+	}
+
+	local := now.In(loc)
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until
+}
+
+// decide returns how an event bound for the channel is handled at the given time
+func (q QuietHoursConfig) decide(channel string, ev Event, now time.Time) quietDecision {
+	if !q.active(channel, now) {
+		return quietSend
+	}
+	if ev.Kind != EventSeatOpen {
+		return quietSkip
+	}
+	if slices.ContainsFunc(ev.CRNs(), func(crn string) bool { return slices.Contains(q.Critical, crn) }) {
+		return quietSend
+	}
+	if q.Channels[channel].Action == "downgrade" {
+		return quietDowngrade
+	}
+	return quietHold
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ===================
+// Quiet hours tests
+// ===================
+
+func TestQuietHours_ActiveWrapsPastMidnightInTimezone(t *testing.T) {
+	q := QuietHoursConfig{
+		Timezone: "America/New_York",
+		Channels: map[string]QuietWindow{"sms": {Start: "23:00", End: "07:00"}},
+	}
+	var errs ConfigErrors
+	if q.validate("quietHours", &errs); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	tests := []struct {
+		utc  string
+		want bool
+	}{
+		{"2026-01-10T03:30:00Z", false}, // 22:30 in New York
+		{"2026-01-10T04:00:00Z", true},  // 23:00
+		{"2026-01-10T09:00:00Z", true},  // 04:00
+		{"2026-01-10T12:00:00Z", false}, // 07:00
+	}
+	for _, tt := range tests {
+		now, _ := time.Parse(time.RFC3339, tt.utc)
+		if got := q.active("sms", now); got != tt.want {
+			t.Errorf("active at %s = %v, want %v", tt.utc, got, tt.want)
+		}
+	}
+	if q.active("email", time.Now()) {
+		t.Error("channels without a window should never be quiet")
+	}
+}
+
+func TestQuietHours_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		q    QuietHoursConfig
+	}{
+		{"bad timezone", QuietHoursConfig{Timezone: "Mars/Olympus"}},
+		{"bad start", QuietHoursConfig{Channels: map[string]QuietWindow{"sms": {Start: "1am", End: "07:00"}}}},
+		{"bad action", QuietHoursConfig{Channels: map[string]QuietWindow{"sms": {Start: "01:00", End: "07:00", Action: "drop"}}}},
+		{"downgrade without fallback", QuietHoursConfig{Channels: map[string]QuietWindow{"sms": {Start: "01:00", End: "07:00", Action: "downgrade"}}}},
+		{"unknown channel", QuietHoursConfig{Channels: map[string]QuietWindow{"pager": {Start: "01:00", End: "07:00"}}}},
+		{"unknown fallback", QuietHoursConfig{Channels: map[string]QuietWindow{"sms": {Start: "01:00", End: "07:00", Action: "downgrade", Fallback: "pager"}}}},
+	}
+	for _, tt := range tests {
+		var errs ConfigErrors
+		if tt.q.validate("quietHours", &errs); len(errs) == 0 {
+			t.Errorf("%s: expected validation error", tt.name)
+		}
+	}
+}
+
+func TestDispatcher_HoldsOpeningsUntilQuietHoursEnd(t *testing.T) {
+	sms := &countingNotifier{name: "sms"}
+	email := &countingNotifier{name: "email"}
+	d := NewDispatcher([]Notifier{email, sms}, AlertPolicy{})
+	d.configure(func(s *dispatchSettings) {
+		s.quiet = &QuietHoursConfig{
+			Timezone: "UTC",
+			Channels: map[string]QuietWindow{"sms": {Start: "01:00", End: "07:00"}},
+		}
+	})
+	now := time.Date(2026, 1, 10, 3, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	d.outbox = openTestOutbox(t, &now)
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+	if email.sent != 1 || sms.sent != 0 {
+		t.Fatalf("during quiet hours: email sent %d, sms sent %d; want 1, 0", email.sent, sms.sent)
+	}
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	d.Flush()
+	now = time.Date(2026, 1, 10, 6, 59, 0, 0, time.UTC)
+	d.RetryDue()
+	if sms.sent != 0 {
+		t.Fatalf("sms sent %d before quiet hours ended", sms.sent)
+	}
+
+	// the held openings survive a restart
+	reopened, err := OpenOutbox(d.outbox.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened.now = func() time.Time { return now }
+	d.outbox = reopened
+	now = time.Date(2026, 1, 10, 7, 0, 0, 0, time.UTC)
+	d.RetryDue()
+
+	if sms.sent != 1 || len(sms.events[0].Batch) != 2 {
+		t.Errorf("expected held openings released as one summary, got %+v", sms.events)
+	}
+	if entries := d.outbox.Entries(); len(entries) != 0 {
+		t.Errorf("expected the outbox emptied after release, got %+v", entries)
+	}
+}
+
+func TestDispatcher_CriticalCRNsIgnoreQuietHours(t *testing.T) {
+	sms := &countingNotifier{name: "sms"}
+	d := NewDispatcher([]Notifier{sms}, AlertPolicy{})
+	d.configure(func(s *dispatchSettings) {
+		s.quiet = &QuietHoursConfig{
+			Channels: map[string]QuietWindow{"sms": {Start: "00:00", End: "23:59"}},
+			Critical: []string{"12345"},
+		}
+	})
+	d.now = func() time.Time { return time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local) }
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	d.Flush()
+
+	if sms.sent != 1 || sms.events[0].CRN != "12345" {
+		t.Errorf("expected only the critical CRN to be sent, got %+v", sms.events)
+	}
+
+	batch := batchEvent([]Event{{Kind: EventSeatOpen, CRN: "67890"}, {Kind: EventSeatOpen, CRN: "12345"}})
+	if got := d.current().quiet.decide("sms", batch, d.now()); got != quietSend {
+		t.Errorf("a batch with a critical CRN should be sent, got %v", got)
+	}
+}
+
+func TestDispatcher_DowngradesToFallbackChannel(t *testing.T) {
+	sms := &countingNotifier{name: "sms"}
+	push := &filteringNotifier{countingNotifier: countingNotifier{name: "ntfy"}, accept: EventError}
+	d := NewDispatcher([]Notifier{sms, push}, AlertPolicy{})
+	d.configure(func(s *dispatchSettings) {
+		s.quiet = &QuietHoursConfig{
+			Channels: map[string]QuietWindow{"sms": {Start: "00:00", End: "23:59", Action: "downgrade", Fallback: "ntfy"}},
+		}
+	})
+	d.now = func() time.Time { return time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local) }
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+
+	if sms.sent != 0 {
+		t.Errorf("sms sent %d during quiet hours, want 0", sms.sent)
+	}
+	if push.sent != 1 || push.events[0].CRN != "12345" {
+		t.Errorf("expected opening downgraded to ntfy, got %+v", push.events)
+	}
+}
+
+func TestDispatcher_DowngradesCRNRoutedOnlyToQuietChannel(t *testing.T) {
+	sms := &countingNotifier{name: "sms"}
+	push := &countingNotifier{name: "ntfy"}
+	d := NewDispatcher([]Notifier{sms, push}, AlertPolicy{})
+	d.configure(func(s *dispatchSettings) {
+		s.quiet = &QuietHoursConfig{
+			Channels: map[string]QuietWindow{"sms": {Start: "00:00", End: "23:59", Action: "downgrade", Fallback: "ntfy"}},
+		}
+		s.channels = map[string][]string{"12345": {"sms"}}
+	})
+	d.now = func() time.Time { return time.Date(2026, 1, 10, 12, 0, 0, 0, time.Local) }
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+
+	if sms.sent != 0 {
+		t.Errorf("sms sent %d during quiet hours, want 0", sms.sent)
+	}
+	if push.sent != 1 || push.events[0].CRN != "12345" {
+		t.Errorf("an opening routed only to sms should still be downgraded to ntfy, got %+v", push.events)
+	}
+}
+
+// filteringNotifier only accepts one kind of event on its own
+type filteringNotifier struct {
+	countingNotifier
+	accept EventKind
+}
+
+func (f *filteringNotifier) Accepts(ev Event) bool { return ev.Kind == f.accept }
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultQuotaPath is where quota counters are kept between runs
+const DefaultQuotaPath = "quota.json"
+
+// quotaWarnPercent is the share of a quota used before the UI warns
+const quotaWarnPercent = 80
+
+// rateLimitBackoff is how long a channel is skipped after its provider answers 429
+const rateLimitBackoff = time.Hour
+
+// errRateLimited marks delivery failures caused by a provider rate limit (HTTP 429)
+var errRateLimited = errors.New("rate limited by provider")
+
+// QuotaConfig caps how many messages each channel sends, to stay inside
+// provider free tiers (e.g. Resend's 100 emails/day)
+type QuotaConfig struct {
+	File     string                  `json:"file"`     // File for sent-message counters (defaults to quota.json)
+	Channels map[string]ChannelQuota `json:"channels"` // Limits per channel name
+}
+
+// ChannelQuota limits one channel. Days and months are counted in UTC, matching
+// how most providers reset their quotas.
+type ChannelQuota struct {
+	Daily    int    `json:"daily"`    // Messages per day (0 = unlimited)
+	Monthly  int    `json:"monthly"`  // Messages per month (0 = unlimited)
+	Fallback string `json:"fallback"` // Channel to use once the quota is used up or the provider rate-limits (optional)
+}
+
+// validate reports limits that aren't sensible and fallbacks that don't point
+// at another channel, under path, e.g. "quotas"
+func (c QuotaConfig) validate(path string, errs *ConfigErrors) {
+	for _, channel := range sortedKeys(c.Channels) {
+		limit, qPath := c.Channels[channel], joinPath(path+".channels", channel)
+		checkChannel(channel, qPath, errs)
+		if limit.Daily < 0 {
+			errs.add(qPath+".daily", "must not be negative, got %d", limit.Daily)
+		}
+		if limit.Monthly < 0 {
+			errs.add(qPath+".monthly", "must not be negative, got %d", limit.Monthly)
+		}
+		switch limit.Fallback {
+		case "":
+		case channel:
+			errs.add(qPath+".fallback", "cannot fall back to itself")
+		default:
+			checkChannel(limit.Fallback, qPath+".fallback", errs)
+		}
+	}
+}
+
+// quotaUsage is the persisted counter state for one channel
+type quotaUsage struct {
+	Day          string    `json:"day"` // YYYY-MM-DD the daily count belongs to
+	DayCount     int       `json:"dayCount"`
+	Month        string    `json:"month"` // YYYY-MM the monthly count belongs to
+	MonthCount   int       `json:"monthCount"`
+	LimitedUntil time.Time `json:"limitedUntil,omitzero"` // set after a provider 429
+}
+
+// QuotaTracker counts messages per profile and channel and persists the
+// counters. Each profile's channels are separate provider accounts, so they
+// share the configured limits but not the counts.
+type QuotaTracker struct {
+	Config QuotaConfig
+
+	mu    sync.Mutex
+	usage map[string]*quotaUsage // keyed by usageKey
+}
+
+// OpenQuotaTracker loads saved counters, starting fresh if there are none
+func OpenQuotaTracker(cfg QuotaConfig) (*QuotaTracker, error) {
+	if cfg.File == "" {
+		cfg.File = DefaultQuotaPath
+	}
+	q := &QuotaTracker{Config: cfg, usage: map[string]*quotaUsage{}}
+
+	data, err := os.ReadFile(cfg.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota file: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &q.usage); err != nil {
+			return nil, fmt.Errorf("failed to parse quota file %s: %w", cfg.File, err)
+		}
+	}
+	return q, nil
+}
+
+// usageKey names a profile's counters for a channel. The default profile's
+// are keyed by channel alone, as they were before profiles existed.
+func usageKey(profile, channel string) string {
+	if profile == "" || profile == defaultProfile {
+		return channel
+	}
+	return profile + "/" + channel
+}
+
+// current returns the counters under key, reset for a new day or month. Callers must hold q.mu.
+func (q *QuotaTracker) current(key string, now time.Time) *quotaUsage {
+	u, ok := q.usage[key]
+	if !ok {
+		u = &quotaUsage{}
+		q.usage[key] = u
+	}
+	now = now.UTC()
+	if day := now.Format(time.DateOnly); u.Day != day {
+		u.Day, u.DayCount = day, 0
+	}
+	if month := now.Format("2006-01"); u.Month != month {
+		u.Month, u.MonthCount = month, 0
+	}
+	return u
+}
+
+// Allow reports whether the profile's channel may send another message, and why not if it can't
+func (q *QuotaTracker) Allow(profile, channel string, now time.Time) (bool, string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	key := usageKey(profile, channel)
+	if _, ok := q.usage[key]; !ok {
+		return true, ""
+	}
+	u := q.current(key, now)
+	limit := q.Config.Channels[channel]
+	switch {
+	case now.Before(u.LimitedUntil):
+		return false, fmt.Sprintf("rate limited until %s", u.LimitedUntil.Local().Format("15:04"))
+	case limit.Daily > 0 && u.DayCount >= limit.Daily:
+		return false, fmt.Sprintf("daily quota of %d used", limit.Daily)
+	case limit.Monthly > 0 && u.MonthCount >= limit.Monthly:
+		return false, fmt.Sprintf("monthly quota of %d used", limit.Monthly)
+	}
+	return true, ""
+}
+
+// Record counts sent provider messages (one per recipient for SMS) and warns
+// when the profile's channel nears or reaches a limit
+func (q *QuotaTracker) Record(profile, channel string, sent int, now time.Time) error {
+	limit, ok := q.Config.Channels[channel]
+	if !ok || sent <= 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	name := usageKey(profile, channel)
+	u := q.current(name, now)
+	u.DayCount += sent
+	u.MonthCount += sent
+	warnQuota(name, "daily", u.DayCount-sent, u.DayCount, limit.Daily)
+	warnQuota(name, "monthly", u.MonthCount-sent, u.MonthCount, limit.Monthly)
+	return q.save()
+}
+
+// warnQuota shows a warning when a count goes from before to used past the
+// warning threshold or the limit
+func warnQuota(channel, period string, before, used, limit int) {
+	if limit <= 0 {
+		return
+	}
+	warnAt := (limit*quotaWarnPercent + 99) / 100
+	if (before < warnAt && used >= warnAt) || (before < limit && used >= limit) {
+		PrintQuotaWarning(channel, period, used, limit)
+	}
+}
+
+// RateLimited pauses a profile's channel after its provider refused a message with 429
+func (q *QuotaTracker) RateLimited(profile, channel string, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.current(usageKey(profile, channel), now).LimitedUntil = now.Add(rateLimitBackoff)
+	return q.save()
+}
+
+// Report warns at startup about channels that are already near their limits
+func (q *QuotaTracker) Report(now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, key := range sortedKeys(q.usage) {
+		channel := key[strings.LastIndex(key, "/")+1:]
+		limit, ok := q.Config.Channels[channel]
+		if !ok {
+			continue
+		}
+		u := q.current(key, now)
+		for _, c := range []struct {
+			period      string
+			used, limit int
+		}{{"daily", u.DayCount, limit.Daily}, {"monthly", u.MonthCount, limit.Monthly}} {
+			if c.limit > 0 && c.used*100 >= c.limit*quotaWarnPercent {
+				PrintQuotaWarning(key, c.period, c.used, c.limit)
+			}
+		}
+	}
+}
+
+// save atomically rewrites the counter file. Callers must hold q.mu.
+func (q *QuotaTracker) save() error {
+	data, err := json.MarshalIndent(q.usage, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode quota counters: %w", err)
+	}
+
+	if err := writeFileAtomic(q.Config.File, data); err != nil {
+		return fmt.Errorf("failed to write quota file: %w", err)
+	}
+	return nil
+}
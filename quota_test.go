@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ===================
+// Quota tests
+// ===================
+
+func openTestQuotas(t *testing.T, channels map[string]ChannelQuota) *QuotaTracker {
+	t.Helper()
+	q, err := OpenQuotaTracker(QuotaConfig{File: filepath.Join(t.TempDir(), "quota.json"), Channels: channels})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestQuotaTracker_DailyLimitResetsAndPersists(t *testing.T) {
+	q := openTestQuotas(t, map[string]ChannelQuota{"email": {Daily: 2, Monthly: 3}})
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	q.Record("", "email", 1, now)
+	q.Record("", "email", 1, now)
+	if ok, _ := q.Allow("", "email", now); ok {
+		t.Fatal("expected daily quota to be used up")
+	}
+	if ok, _ := q.Allow("", "sms", now); !ok {
+		t.Error("channels without a quota should always be allowed")
+	}
+
+	reloaded, err := OpenQuotaTracker(q.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := reloaded.Allow("", "email", now); ok {
+		t.Error("expected counters to survive a restart")
+	}
+
+	tomorrow := now.Add(24 * time.Hour)
+	if ok, _ := reloaded.Allow("", "email", tomorrow); !ok {
+		t.Fatal("expected daily quota to reset the next day")
+	}
+	reloaded.Record("", "email", 1, tomorrow)
+	if ok, reason := reloaded.Allow("", "email", tomorrow); ok || reason != "monthly quota of 3 used" {
+		t.Errorf("Allow = %v, %q; want monthly quota exhausted", ok, reason)
+	}
+}
+
+func TestQuotaTracker_RateLimitedPausesChannel(t *testing.T) {
+	q := openTestQuotas(t, nil)
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	q.RateLimited("", "ntfy", now)
+	if ok, _ := q.Allow("", "ntfy", now.Add(30*time.Minute)); ok {
+		t.Error("expected channel paused after 429")
+	}
+	if ok, _ := q.Allow("", "ntfy", now.Add(rateLimitBackoff)); !ok {
+		t.Error("expected channel allowed once the backoff passes")
+	}
+}
+
+func TestQuotaTracker_ProfilesCountSeparately(t *testing.T) {
+	q := openTestQuotas(t, map[string]ChannelQuota{"sms": {Daily: 1}})
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+
+	q.Record("alice", "sms", 1, now)
+	if ok, _ := q.Allow("alice", "sms", now); ok {
+		t.Error("expected alice's daily quota to be used up")
+	}
+	if ok, _ := q.Allow("bob", "sms", now); !ok {
+		t.Error("alice's texts should not count against bob's account")
+	}
+	if ok, _ := q.Allow(defaultProfile, "sms", now); !ok {
+		t.Error("alice's texts should not count against the default profile's account")
+	}
+}
+
+func TestDispatcher_QuotaCountsEveryText(t *testing.T) {
+	api := newPartialTwilio(t, "+15552222222", http.StatusInternalServerError)
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	d := newPartialSMSDispatcher(t, api, AlertPolicy{}, &now)
+	d.quotas = openTestQuotas(t, map[string]ChannelQuota{"sms": {Daily: 10}})
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush() // reaches one of the two recipients
+	api.recover()
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	d.Flush()
+
+	if used := d.quotas.usage["sms"].DayCount; used != 3 {
+		t.Errorf("quota counted %d texts, want 3", used)
+	}
+}
+
+// limitedNotifier fails with a rate limit error
+type limitedNotifier struct{ countingNotifier }
+
+func (l *limitedNotifier) Notify(ev Event) error {
+	l.sent++
+	return fmt.Errorf("%w: 429 Too Many Requests", errRateLimited)
+}
+
+func TestDispatcher_FallsBackWhenQuotaUsed(t *testing.T) {
+	email := &countingNotifier{name: "email"}
+	sms := &filteringNotifier{countingNotifier: countingNotifier{name: "sms"}, accept: EventError}
+	d := NewDispatcher([]Notifier{email, sms}, AlertPolicy{})
+	d.quotas = openTestQuotas(t, map[string]ChannelQuota{"email": {Daily: 1, Fallback: "sms"}})
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	d.Flush()
+
+	if email.sent != 1 {
+		t.Errorf("email sent %d, want 1 (quota)", email.sent)
+	}
+	if sms.sent != 1 || sms.events[0].CRN != "67890" {
+		t.Errorf("expected second opening to fall back to sms, got %+v", sms.events)
+	}
+}
+
+func TestDispatcher_FallsBackOnProviderRateLimit(t *testing.T) {
+	push := &limitedNotifier{countingNotifier{name: "ntfy"}}
+	sms := &filteringNotifier{countingNotifier: countingNotifier{name: "sms"}, accept: EventError}
+	d := NewDispatcher([]Notifier{push, sms}, AlertPolicy{})
+	d.quotas = openTestQuotas(t, map[string]ChannelQuota{"ntfy": {Fallback: "sms"}})
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345"})
+	d.Flush()
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890"})
+	d.Flush()
+
+	if push.sent != 1 {
+		t.Errorf("ntfy attempted %d times, want 1 (paused after 429)", push.sent)
+	}
+	if sms.sent != 2 {
+		t.Errorf("sms sent %d, want both openings", sms.sent)
+	}
+}
+
+func TestDispatcher_FallsBackForCRNsRoutedAwayFromFallback(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	email := &countingNotifier{name: "email"}
+	sms := &flakyNotifier{name: "sms"}
+	d := NewDispatcher([]Notifier{email, sms}, AlertPolicy{})
+	d.now = func() time.Time { return now }
+	d.outbox = openTestOutbox(t, &now)
+	d.quotas = openTestQuotas(t, map[string]ChannelQuota{"sms": {Daily: 1, Fallback: "email"}})
+	d.configure(func(s *dispatchSettings) { s.channels = map[string][]string{"12345": {"sms"}, "67890": {"sms"}} })
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345", Time: now})
+	d.Flush() // uses the day's only text
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890", Time: now})
+	d.Flush()
+	if email.sent != 1 || email.events[0].CRN != "67890" {
+		t.Errorf("expected the opening only watched by sms to fall back to email, got %+v", email.events)
+	}
+
+	// a retry blocked by the quota is handed to the fallback rather than dropped
+	if _, err := d.outbox.Add("sms", Event{Kind: EventSeatOpen, CRN: "12345", Time: now}); err != nil {
+		t.Fatal(err)
+	}
+	d.RetryDue()
+	if email.sent != 2 || email.events[1].CRN != "12345" {
+		t.Errorf("expected the blocked retry sent via email, got %+v", email.events)
+	}
+}
+
+func TestDoNotifyRequest_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	n := &NtfyNotifier{Config: NtfyConfig{Server: server.URL, Topic: "seats"}}
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345"}); !errors.Is(err, errRateLimited) {
+		t.Errorf("expected rate limit error, got %v", err)
+	}
+}
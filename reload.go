@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// restartSections are config sections only read at startup, so edits to them
+// are reported but not applied until the monitor restarts
+var restartSections = []string{"outbox", "escalation", "quotas", "heartbeat", "resendApiKey"}
+
+// ConfigWatcher notices edits to the config file while the monitor runs
+type ConfigWatcher struct {
+	src     ConfigSources
+	modTime time.Time
+	size    int64
+	data    []byte
+}
+
+// NewConfigWatcher starts watching the config file described by src from its current contents
+func NewConfigWatcher(src ConfigSources) *ConfigWatcher {
+	w := &ConfigWatcher{src: src}
+	if info, err := os.Stat(src.Path); err == nil {
+		w.modTime, w.size = info.ModTime(), info.Size()
+		w.data, _ = os.ReadFile(src.Path)
+	}
+	return w
+}
+
+// Poll reloads the config when the file's contents have changed since the last
+// call. It reports false when nothing changed. A changed file that fails to
+// load or validate returns the error, and isn't reported again until it changes.
+func (w *ConfigWatcher) Poll() (Config, bool, error) {
+	info, err := os.Stat(w.src.Path)
+	if err != nil {
+		// editors may briefly remove the file while saving
+		return Config{}, false, nil
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return Config{}, false, nil
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	data, err := os.ReadFile(w.src.Path)
+	if err != nil || bytes.Equal(data, w.data) {
+		return Config{}, false, nil
+	}
+	w.data = data
+
+	cfg, err := loadConfigFrom(w.src)
+	if err != nil {
+		return Config{}, true, err
+	}
+	return cfg, true, nil
+}
+
+// ConfigChange is one setting that differs between two configs
+type ConfigChange struct {
+	Path     string // JSON path, e.g. "ntfy.topic"
+	Old, New string // rendered values, "" when unset
+}
+
+// ConfigDiff describes what a reload changed
+type ConfigDiff struct {
+	AddedCRNs   []string
+	RemovedCRNs []string
+	Changes     []ConfigChange // every other setting, secrets masked
+	Restart     []string       // changed sections that need a restart to apply
+}
+
+// Empty reports whether the reload changed nothing
+func (d ConfigDiff) Empty() bool {
+	return len(d.AddedCRNs) == 0 && len(d.RemovedCRNs) == 0 && len(d.Changes) == 0
+}
+
+// Touches reports whether any setting in a top-level section changed
+func (d ConfigDiff) Touches(section string) bool {
+	return slices.ContainsFunc(d.Changes, func(c ConfigChange) bool {
+		return c.Path == section || strings.HasPrefix(c.Path, section+".")
+	})
+}
+
+// diffConfig compares two configs setting by setting
+func diffConfig(old, next Config) ConfigDiff {
+	var diff ConfigDiff
+	// CRNs are added and removed across all profiles; changes to a profile's
+	// watches show up under profiles[i].crns
+	oldCRNs, nextCRNs := old.watchList().CRNs(), next.watchList().CRNs()
+	for _, crn := range nextCRNs {
+		if !slices.Contains(oldCRNs, crn) {
+			diff.AddedCRNs = append(diff.AddedCRNs, crn)
+		}
+	}
+	for i, w := range next.CRNs {
+		if !slices.Contains(old.CRNs.CRNs(), w.CRN) {
+			continue
+		}
+		if prev := old.CRNs.Get(w.CRN); !reflect.DeepEqual(prev, w) {
+			before, _ := json.Marshal(prev)
+			after, _ := json.Marshal(w)
+			diff.Changes = append(diff.Changes, ConfigChange{Path: fmt.Sprintf("crns[%d]", i), Old: string(before), New: string(after)})
+		}
+	}
+	for _, crn := range oldCRNs {
+		if !slices.Contains(nextCRNs, crn) {
+			diff.RemovedCRNs = append(diff.RemovedCRNs, crn)
+		}
+	}
+
+	// secrets are masked when flattened, so their values are compared separately
+	oldValues, newValues := flattenConfig(old), flattenConfig(next)
+	oldSecrets, newSecrets := secretValues(old), secretValues(next)
+	paths := map[string]bool{}
+	for path := range oldValues {
+		paths[path] = true
+	}
+	for path := range newValues {
+		paths[path] = true
+	}
+	restart := map[string]bool{}
+	for path := range paths {
+		if oldValues[path] == newValues[path] && oldSecrets[path] == newSecrets[path] {
+			continue
+		}
+		diff.Changes = append(diff.Changes, ConfigChange{Path: path, Old: oldValues[path], New: newValues[path]})
+
+		section, _, _ := strings.Cut(path, ".")
+		if slices.Contains(restartSections, section) && !restart[section] {
+			restart[section] = true
+			diff.Restart = append(diff.Restart, section)
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool { return diff.Changes[i].Path < diff.Changes[j].Path })
+	sort.Strings(diff.Restart)
+	return diff
+}
+
+// flattenConfig maps the JSON path of every set value, other than the CRN
+// list, to its JSON rendering. Lists of objects (like profiles) are walked item
+// by item so secrets inside them are still masked; other lists are rendered
+// whole, and zero values are left out. Secrets are shown as "(hidden)".
+func flattenConfig(cfg Config) map[string]string {
+	secrets := secretValues(cfg)
+	values := map[string]string{}
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return values
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return values
+	}
+	delete(raw, "crns")
+
+	var walk func(path string, v any)
+	walk = func(path string, v any) {
+		switch value := v.(type) {
+		case nil:
+			return
+		case map[string]any:
+			for key, item := range value {
+				walk(joinPath(path, key), item)
+			}
+			return
+		case []any:
+			objects := len(value) > 0 && !slices.ContainsFunc(value, func(item any) bool {
+				_, ok := item.(map[string]any)
+				return !ok
+			})
+			if objects {
+				for i, item := range value {
+					walk(fmt.Sprintf("%s[%d]", path, i), item)
+				}
+				return
+			}
+		}
+		// zero values mean "unset" throughout the config
+		rendered, _ := json.Marshal(v)
+		if r := string(rendered); r != `""` && r != "0" && r != "false" && r != "[]" {
+			if _, secret := secrets[path]; secret {
+				r = maskSecret(r)
+			}
+			values[path] = r
+		}
+	}
+	walk("", raw)
+	return values
+}
+
+// secretValues maps the path of every Secret in cfg to its value
+func secretValues(cfg Config) map[string]string {
+	values := map[string]string{}
+	for _, s := range configSecrets(&cfg) {
+		values[s.Path] = s.Value.String()
+	}
+	return values
+}
+
+// maskSecret hides a credential while still showing whether it is set
+func maskSecret(value string) string {
+	if value == "" {
+		return value
+	}
+	return "(hidden)"
+}
+
+// applyConfig switches a running monitor to a reloaded config: channels,
+// alert rules, quiet hours and templates are replaced, and CRNs added to or
+// removed from the file are added to or removed from the watch list.
+// Channels whose settings didn't change keep their state.
+func applyConfig(old, next Config, diff ConfigDiff, monitor *Monitor, fanout *Fanout) error {
+	templates, err := LoadTemplates(next.Templates)
+	if err != nil {
+		return err
+	}
+	if err := fanout.Reconfigure(next, templates); err != nil {
+		return err
+	}
+	monitor.SetConfig(next)
+
+	// relabel watches whose label changed
+	oldWatches := old.watchList()
+	for _, w := range next.watchList() {
+		if slices.Contains(diff.AddedCRNs, w.CRN) || oldWatches.Get(w.CRN).Label == w.Label {
+			continue
+		}
+		if title, err := next.getCourseName(w.CRN); err == nil {
+			monitor.rename(w.CRN, w.displayName(title))
+		}
+	}
+
+	for _, crn := range diff.RemovedCRNs {
+		if monitor.RemoveCRN(crn) {
+			monitor.Acknowledge(crn)
+			PrintCourseRemoved(crn)
+		}
+	}
+	for _, crn := range diff.AddedCRNs {
+		course, err := monitor.AddCRN(crn)
+		if err != nil {
+			PrintCourseNotFound(crn)
+			continue
+		}
+		PrintCourseFound(course.CRN, course.Name)
+	}
+	return nil
+}
+
+// reuseNotifiers keeps the running instance of every channel whose config
+// section is unchanged, so state like Telegram's update offset survives a reload
+func reuseNotifiers(running, built []Notifier, old, next Config) []Notifier {
+	oldSections := reflect.ValueOf(old)
+	nextSections := reflect.ValueOf(next)
+	fields := jsonFields(reflect.TypeOf(Config{}))
+
+	notifiers := make([]Notifier, 0, len(built))
+	for _, n := range built {
+		field, ok := fields[n.Name()]
+		if ok && reflect.DeepEqual(oldSections.FieldByIndex(field.Index).Interface(), nextSections.FieldByIndex(field.Index).Interface()) {
+			if i := slices.IndexFunc(running, func(r Notifier) bool { return r.Name() == n.Name() }); i >= 0 {
+				n = running[i]
+			}
+		}
+		notifiers = append(notifiers, n)
+	}
+	return notifiers
+}
+
+// listeners runs Listen for every channel that accepts commands, and can be
+// updated to match a reloaded set of channels
+type listeners struct {
+	running map[commandListener]runningListener
+}
+
+// runningListener is a Listen call with the channel that stops it and the
+// channel closed once it has returned
+type runningListener struct {
+	stop, done chan struct{}
+}
+
+// sync starts listeners for new channels, each with the control it answers
+// to, and stops those for removed ones. Removed listeners have returned before
+// new ones start, so a bot whose settings changed is never polled twice at once.
+func (l *listeners) sync(targets map[commandListener]MonitorControl) {
+	if l.running == nil {
+		l.running = map[commandListener]runningListener{}
+	}
+	var stopped []runningListener
+	for cl, r := range l.running {
+		if _, keep := targets[cl]; !keep {
+			close(r.stop)
+			stopped = append(stopped, r)
+			delete(l.running, cl)
+		}
+	}
+	for _, r := range stopped {
+		<-r.done
+	}
+
+	for cl, ctl := range targets {
+		if _, running := l.running[cl]; !running {
+			r := runningListener{stop: make(chan struct{}), done: make(chan struct{})}
+			l.running[cl] = r
+			go func() {
+				defer close(r.done)
+				cl.Listen(r.stop, ctl)
+			}()
+		}
+	}
+}
+
+// stopAll stops every listener
+func (l *listeners) stopAll() {
+	l.sync(nil)
+}
+
+// describeChange renders a change for the reload summary
+func describeChange(c ConfigChange) string {
+	switch {
+	case c.Old == "":
+		return fmt.Sprintf("%s: set to %s", c.Path, c.New)
+	case c.New == "":
+		return fmt.Sprintf("%s: removed (was %s)", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("%s: %s → %s", c.Path, c.Old, c.New)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ===================
+// Config reload tests
+// ===================
+
+// rewriteConfig replaces a config file, moving its modification time forward so the change is seen
+func rewriteConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigWatcher_Poll(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"crns": ["12345"]}`)
+	w := NewConfigWatcher(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+
+	if _, changed, err := w.Poll(); changed || err != nil {
+		t.Fatalf("unchanged file: changed=%v err=%v", changed, err)
+	}
+
+	rewriteConfig(t, path, `{"crns": ["12345", "67890"], "checkInterval": 60}`)
+	cfg, changed, err := w.Poll()
+	if !changed || err != nil {
+		t.Fatalf("edited file: changed=%v err=%v", changed, err)
+	}
+	if len(cfg.CRNs) != 2 || cfg.CheckInterval != 60 {
+		t.Errorf("unexpected reloaded config: %+v", cfg)
+	}
+
+	// touching the file without changing it isn't a reload
+	rewriteConfig(t, path, `{"crns": ["12345", "67890"], "checkInterval": 60}`)
+	if _, changed, _ := w.Poll(); changed {
+		t.Error("expected an unchanged file to be ignored")
+	}
+
+	rewriteConfig(t, path, `{"crns": ["1234"]}`)
+	if _, changed, err := w.Poll(); !changed || err == nil || !strings.Contains(err.Error(), "crns[0]") {
+		t.Errorf("invalid edit: changed=%v err=%v", changed, err)
+	}
+	if _, changed, _ := w.Poll(); changed {
+		t.Error("expected an invalid edit to be reported once")
+	}
+}
+
+func TestDiffConfig(t *testing.T) {
+	old := Config{CRNs: newWatchList("12345", "67890"), CheckInterval: 30, Email: "a@vt.edu",
+		Telegram:  &TelegramConfig{Token: "old-token", ChatID: "1"},
+		Heartbeat: &HeartbeatConfig{URL: "https://hc-ping.com/old-uuid"},
+		Profiles:  []ProfileConfig{{Name: "alice", SMS: &SMSConfig{AccountSID: "AC1", AuthToken: "old-auth"}}}}
+	next := Config{CRNs: newWatchList("12345", "11111"), CheckInterval: 60, Outbox: "retry.json",
+		Telegram:  &TelegramConfig{Token: "new-token", ChatID: "1"},
+		Heartbeat: &HeartbeatConfig{URL: "https://hc-ping.com/new-uuid"},
+		Profiles:  []ProfileConfig{{Name: "alice", SMS: &SMSConfig{AccountSID: "AC1", AuthToken: "new-auth"}}}}
+
+	diff := diffConfig(old, next)
+	if len(diff.AddedCRNs) != 1 || diff.AddedCRNs[0] != "11111" {
+		t.Errorf("added = %v", diff.AddedCRNs)
+	}
+	if len(diff.RemovedCRNs) != 1 || diff.RemovedCRNs[0] != "67890" {
+		t.Errorf("removed = %v", diff.RemovedCRNs)
+	}
+
+	var lines []string
+	for _, c := range diff.Changes {
+		lines = append(lines, describeChange(c))
+	}
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"checkInterval: 30 → 60",
+		`email: removed (was "a@vt.edu")`,
+		`outbox: set to "retry.json"`,
+		"telegram.token: (hidden) → (hidden)",
+		"heartbeat.url: (hidden) → (hidden)",
+		"profiles[0].sms.authToken: (hidden) → (hidden)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diff missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "new-") || strings.Contains(got, "telegram.chatId") {
+		t.Errorf("diff shows a secret or an unchanged value:\n%s", got)
+	}
+	if len(diff.Restart) != 2 || diff.Restart[0] != "heartbeat" || diff.Restart[1] != "outbox" {
+		t.Errorf("restart = %v", diff.Restart)
+	}
+	if !diff.Touches("telegram") || diff.Touches("digest") {
+		t.Error("Touches reported the wrong sections")
+	}
+}
+
+func TestApplyConfig_UpdatesWatchListAndChannels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<table class="dataentrytable"><tr><td>67890</td><td>001</td><td>Data Structures</td></tr></table>`))
+	}))
+	defer server.Close()
+
+	old := Config{CRNs: newWatchList("12345"), BaseURL: server.URL, Ntfy: &NtfyConfig{Topic: "seats"}, Gotify: &GotifyConfig{Server: "http://gotify"}}
+	next := Config{CRNs: newWatchList("67890"), BaseURL: server.URL, Ntfy: &NtfyConfig{Topic: "seats"}, Gotify: &GotifyConfig{Server: "http://gotify2"},
+		Alerts: AlertPolicy{Cooldown: 300}}
+
+	fanout, err := NewFanout(old, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dispatcher := fanout.Profiles[0]
+	ntfy, gotify := dispatcher.current().notifiers[0], dispatcher.current().notifiers[1]
+	monitor := NewMonitor(old, []CourseStatus{{CRN: "12345", Name: "Intro to Testing"}})
+
+	if err := applyConfig(old, next, diffConfig(old, next), monitor, fanout); err != nil {
+		t.Fatal(err)
+	}
+
+	status := monitor.Status()
+	if len(status) != 1 || status[0].CRN != "67890" || status[0].Name != "Data Structures" {
+		t.Errorf("unexpected watch list: %+v", status)
+	}
+	if len(dispatcher.current().notifiers) != 2 || dispatcher.current().notifiers[0] != ntfy {
+		t.Error("expected the unchanged ntfy channel to be kept")
+	}
+	if dispatcher.current().notifiers[1] == gotify {
+		t.Error("expected the changed gotify channel to be rebuilt")
+	}
+	if dispatcher.current().policy.Cooldown != 300 {
+		t.Errorf("alert policy not applied: %+v", dispatcher.current().policy)
+	}
+}
+
+// slowListener takes a while to return after it is stopped, and records how
+// many listeners for the same bot were running at once
+type slowListener struct {
+	active, most *atomic.Int32
+}
+
+func (l *slowListener) Listen(stop <-chan struct{}, ctl MonitorControl) {
+	running := l.active.Add(1)
+	if running > l.most.Load() {
+		l.most.Store(running)
+	}
+	<-stop
+	time.Sleep(50 * time.Millisecond)
+	l.active.Add(-1)
+}
+
+func TestListeners_SyncWaitsForStoppedListeners(t *testing.T) {
+	var active, most atomic.Int32
+	old, next := &slowListener{&active, &most}, &slowListener{&active, &most}
+	monitor := NewMonitor(Config{}, nil)
+
+	var l listeners
+	l.sync(map[commandListener]MonitorControl{old: monitor})
+	l.sync(map[commandListener]MonitorControl{next: monitor})
+	l.stopAll()
+
+	if most.Load() != 1 || active.Load() != 0 {
+		t.Errorf("listeners overlapped: at most %d running, %d still running", most.Load(), active.Load())
+	}
+}
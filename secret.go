@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// secretCommandTimeout bounds how long a cmd: secret reference may run
+const secretCommandTimeout = 10 * time.Second
+
+// Secret is a credential in the config: an API key, password or token. It is
+// written either inline or as a reference resolved at startup:
+//
+//	file:/run/secrets/resend   contents of a file
+//	env:RESEND_API_KEY         an environment variable
+//	cmd:pass show resend       output of a shell command
+//
+// Secrets print as "(hidden)" so they can't end up in logs or error messages.
+type Secret string
+
+// String hides the value when a secret is printed
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "(hidden)"
+}
+
+// GoString hides the value when a secret is printed with %#v
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// Value returns the secret itself, for use in requests
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// reference splits a secret reference into its scheme and argument
+func (s Secret) reference() (scheme, arg string, ok bool) {
+	scheme, arg, found := strings.Cut(string(s), ":")
+	switch scheme {
+	case "file", "env", "cmd":
+		return scheme, strings.TrimSpace(arg), found
+	}
+	return "", "", false
+}
+
+// resolve returns the value a reference points to, or the secret itself if it is inline.
+// Errors describe the reference, never the value.
+func (s Secret) resolve(lookupEnv func(string) (string, bool)) (Secret, error) {
+	scheme, arg, ok := s.reference()
+	if !ok {
+		return s, nil
+	}
+	if arg == "" {
+		return "", fmt.Errorf("%s: reference is empty", scheme)
+	}
+
+	var value string
+	switch scheme {
+	case "file":
+		data, err := os.ReadFile(arg)
+		if err != nil {
+			return "", fmt.Errorf("file:%s: %w", arg, errors.Unwrap(err))
+		}
+		value = string(data)
+	case "env":
+		v, found := lookupEnv(arg)
+		if !found {
+			return "", fmt.Errorf("env:%s: variable is not set", arg)
+		}
+		value = v
+	case "cmd":
+		ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+		defer cancel()
+		// stdout is the secret and stderr may echo it, so neither goes into the error
+		out, err := shellCommand(ctx, arg).Output()
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("cmd: %q timed out after %s", arg, secretCommandTimeout)
+		}
+		if err != nil {
+			return "", fmt.Errorf("cmd: %q failed: %v", arg, commandExitError(err))
+		}
+		value = string(out)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s:%s: is empty", scheme, arg)
+	}
+	return Secret(value), nil
+}
+
+// commandExitError reduces a command error to its exit status
+func commandExitError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s", exitErr.ProcessState)
+	}
+	return err
+}
+
+// redactedError is an error message with a secret blanked out. It still
+// unwraps to the original, so errors.Is keeps working.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// hideSecret blanks s out of err's message, for APIs like Telegram's that put
+// the credential in the request URL
+func hideSecret(err error, s Secret) error {
+	if err == nil || s == "" || !strings.Contains(err.Error(), s.Value()) {
+		return err
+	}
+	return &redactedError{msg: strings.ReplaceAll(err.Error(), s.Value(), s.String()), err: err}
+}
+
+// configSecret is a secret field found in a config, with its JSON path
+type configSecret struct {
+	Path  string
+	Value reflect.Value
+}
+
+// configSecrets finds every Secret field in cfg, including those inside
+// optional sections and profiles. The values are settable.
+func configSecrets(cfg *Config) []configSecret {
+	var secrets []configSecret
+	var walk func(path string, v reflect.Value)
+	walk = func(path string, v reflect.Value) {
+		switch {
+		case v.Type() == reflect.TypeOf(Secret("")):
+			secrets = append(secrets, configSecret{Path: path, Value: v})
+		case v.Kind() == reflect.Pointer:
+			if !v.IsNil() {
+				walk(path, v.Elem())
+			}
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+			for i := range v.Len() {
+				walk(fmt.Sprintf("%s[%d]", path, i), v.Index(i))
+			}
+		case v.Kind() == reflect.Struct:
+			for i := range v.NumField() {
+				field := v.Type().Field(i)
+				key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				if key == "" || key == "-" || !field.IsExported() {
+					continue
+				}
+				walk(joinPath(path, key), v.Field(i))
+			}
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return secrets
+}
+
+// resolveSecrets replaces every secret reference in cfg with the value it points to
+func resolveSecrets(cfg *Config, lookupEnv func(string) (string, bool)) ConfigErrors {
+	var errs ConfigErrors
+	for _, s := range configSecrets(cfg) {
+		secret := Secret(s.Value.String())
+		value, err := secret.resolve(lookupEnv)
+		if err != nil {
+			errs.add(s.Path, "%v", err)
+			continue
+		}
+		if scheme, arg, ok := secret.reference(); ok && scheme == "env" {
+			cfg.secretEnv = append(cfg.secretEnv, arg)
+		}
+		s.Value.SetString(string(value))
+	}
+	return errs
+}
+
+// inlineSecrets lists the paths of secrets written directly in cfg rather than as references
+func inlineSecrets(cfg Config) []string {
+	var paths []string
+	for _, s := range configSecrets(&cfg) {
+		secret := Secret(s.Value.String())
+		if _, _, ok := secret.reference(); secret != "" && !ok {
+			paths = append(paths, s.Path)
+		}
+	}
+	return paths
+}
+
+// checkSecretPermissions refuses a config file that any user on the machine
+// can read when it holds secrets inline
+func checkSecretPermissions(path string, cfg Config) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm()&0o004 == 0 {
+		return nil
+	}
+	inline := inlineSecrets(cfg)
+	if len(inline) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s is readable by every user on this machine and contains secrets (%s); run chmod 600 %s, or use file:, env: or cmd: references instead",
+		path, strings.Join(inline, ", "), path)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ===================
+// Secret reference tests
+// ===================
+
+func TestSecret_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "resend")
+	os.WriteFile(keyFile, []byte("re_from_file\n"), 0o600)
+	env := fakeEnv(map[string]string{"BOT_TOKEN": "123:from-env"})
+
+	tests := []struct {
+		secret Secret
+		want   string
+	}{
+		{"inline-token", "inline-token"},
+		{Secret("file:" + keyFile), "re_from_file"},
+		{"env:BOT_TOKEN", "123:from-env"},
+		{"cmd:echo from-command", "from-command"},
+	}
+	for _, tt := range tests {
+		got, err := tt.secret.resolve(env)
+		if err != nil || got.Value() != tt.want {
+			t.Errorf("resolve(%s) = %q, %v; want %q", tt.secret.Value(), got.Value(), err, tt.want)
+		}
+	}
+
+	// the failing command prints 42, which must not appear in the error
+	for _, bad := range []Secret{"env:MISSING", Secret("file:" + filepath.Join(dir, "nope")), "cmd:echo $((6*7)); echo $((6*7)) >&2; exit 3", "cmd:true", "env:"} {
+		_, err := bad.resolve(env)
+		if err == nil {
+			t.Errorf("resolve(%s) should fail", bad.Value())
+			continue
+		}
+		if strings.HasPrefix(bad.Value(), "cmd:") && strings.Contains(err.Error(), "42") {
+			t.Errorf("error shows the command's output: %v", err)
+		}
+	}
+}
+
+func TestSecret_NeverPrinted(t *testing.T) {
+	cfg := TelegramConfig{Token: "123:super-secret", ChatID: "42"}
+	for _, out := range []string{fmt.Sprint(cfg.Token), fmt.Sprintf("%v", cfg), fmt.Sprintf("%+v", cfg), fmt.Sprintf("%#v", cfg)} {
+		if strings.Contains(out, "super-secret") {
+			t.Errorf("secret printed: %s", out)
+		}
+	}
+
+	n := &TelegramNotifier{Config: TelegramConfig{Token: "123:super-secret", ChatID: "42", APIURL: "http://127.0.0.1:1"}}
+	err := n.Notify(Event{Kind: EventDigest, Message: "hi"})
+	if err == nil || strings.Contains(err.Error(), "super-secret") {
+		t.Errorf("expected an error without the bot token, got %v", err)
+	}
+}
+
+func TestLoadConfig_ResolvesSecretReferences(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "gotify")
+	os.WriteFile(keyFile, []byte("gotify-token\n"), 0o600)
+	path := createTempConfig(t, `{
+		"crns": ["12345"],
+		"resendApiKey": "env:RESEND_KEY",
+		"heartbeat": {"url": "env:HC_URL"},
+		"gotify": {"server": "http://gotify", "token": "file:`+keyFile+`"},
+		"profiles": [{"name": "alex", "crns": ["12345"], "ntfy": {"topic": "alex", "token": "cmd:printf tk_alex"}}]
+	}`)
+
+	cfg, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(map[string]string{"RESEND_KEY": "re_123", "HC_URL": "https://hc-ping.com/abc"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Heartbeat.URL != "https://hc-ping.com/abc" {
+		t.Errorf("heartbeat URL not resolved: %q", cfg.Heartbeat.URL.Value())
+	}
+	if cfg.resendAPIKey() != "re_123" || cfg.Gotify.Token != "gotify-token" || cfg.Profiles[0].Ntfy.Token != "tk_alex" {
+		t.Errorf("references not resolved: %q %q %q", cfg.ResendAPIKey.Value(), cfg.Gotify.Token.Value(), cfg.Profiles[0].Ntfy.Token.Value())
+	}
+
+	path = createTempConfig(t, `{"crns": ["12345"], "telegram": {"token": "env:NOPE", "chatId": "1"}}`)
+	_, err = loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil || !strings.Contains(err.Error(), "telegram.token: env:NOPE: variable is not set") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfig_RefusesWorldReadableInlineSecrets(t *testing.T) {
+	path := createTempConfig(t, `{"crns": ["12345"], "sms": {"accountSid": "AC1", "authToken": "inline", "from": "+15550000000", "to": ["+15551111111"]}}`)
+	os.Chmod(path, 0o644)
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil || !strings.Contains(err.Error(), "readable by every user") || !strings.Contains(err.Error(), "sms.authToken") {
+		t.Fatalf("expected a permissions error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "inline\"") {
+		t.Errorf("error shows the secret: %v", err)
+	}
+
+	os.Chmod(path, 0o600)
+	if _, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)}); err != nil {
+		t.Errorf("a private config should load: %v", err)
+	}
+
+	// references are fine in a shared file
+	path = createTempConfig(t, `{"crns": ["12345"], "ntfy": {"topic": "seats", "token": "env:NTFY_TOKEN"}}`)
+	os.Chmod(path, 0o644)
+	if _, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(map[string]string{"NTFY_TOKEN": "tk"})}); err != nil {
+		t.Errorf("references shouldn't need a private file: %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTwilioAPI is the Twilio REST API base URL
+const DefaultTwilioAPI = "https://api.twilio.com"
+
+// smsStatusPolls is how many times delivery status is checked before giving up
+const smsStatusPolls = 6
+
+// SMSConfig configures text message alerts through the Twilio Messages API
+// (or any service exposing the same REST interface)
+type SMSConfig struct {
+	AccountSID string   `json:"accountSid"` // Twilio account SID (required)
+	AuthToken  Secret   `json:"authToken"`  // Twilio auth token (required)
+	From       string   `json:"from"`       // Sending phone number in E.164 format (required)
+	To         []string `json:"to"`         // Recipient phone numbers in E.164 format (required)
+	CRNs       []string `json:"crns"`       // Only text for these CRNs (optional, defaults to all)
+	APIURL     string   `json:"apiUrl"`     // API base URL (optional, for testability) (defaults to api.twilio.com)
+}
+
+// SMSMessage records a sent text message and its last known delivery status
+type SMSMessage struct {
+	SID    string
+	CRN    string
+	To     string
+	Status string
+	Sent   time.Time
+}
+
+// SMSNotifier texts seat openings for the configured CRNs
+type SMSNotifier struct {
+	Config SMSConfig
+
+	pollInterval time.Duration // delay between delivery status checks (defaults to 10s)
+
+	mu       sync.Mutex
+	messages []SMSMessage // sent recently enough that their delivery may still be tracked
+}
+
+func (n *SMSNotifier) Name() string { return "sms" }
+
+// Accepts limits text messages to seat openings for the configured CRNs
+func (n *SMSNotifier) Accepts(ev Event) bool {
+	if ev.Kind != EventSeatOpen {
+		return false
+	}
+	return len(n.Config.CRNs) == 0 || slices.Contains(n.Config.CRNs, ev.CRN)
+}
+
+// recipients returns the numbers ev is texted to: every configured number,
+// or only the ones an earlier partial send missed when retrying it
+func (n *SMSNotifier) recipients(ev Event) []string {
+	if len(ev.Recipients) > 0 {
+		return ev.Recipients
+	}
+	return n.Config.To
+}
+
+func (n *SMSNotifier) Notify(ev Event) error {
+	cfg := n.Config
+	if cfg.AccountSID == "" || cfg.AuthToken == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return fmt.Errorf("sms accountSid, authToken, from and to must be set")
+	}
+
+	body := ev.Body()
+	if len(ev.Batch) == 0 {
+		body += " " + RegistrationURL
+	}
+	recipients := n.recipients(ev)
+
+	var errs, failed []string
+	var limited error
+	for i, to := range recipients {
+		msg, err := n.send(to, body)
+		if errors.Is(err, errRateLimited) {
+			// the rest would be refused too
+			limited = err
+			failed = append(failed, recipients[i:]...)
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", to, err))
+			failed = append(failed, to)
+			continue
+		}
+		msg.CRN = strings.Join(ev.CRNs(), ",")
+		msg.Sent = time.Now()
+
+		n.mu.Lock()
+		// older messages are past tracking and would only pile up
+		tracked := smsStatusPolls * n.interval()
+		n.messages = slices.DeleteFunc(n.messages, func(m SMSMessage) bool { return msg.Sent.Sub(m.Sent) > tracked })
+		n.messages = append(n.messages, msg)
+		n.mu.Unlock()
+
+		go n.trackDelivery(msg.SID)
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	err := limited
+	switch {
+	case err == nil:
+		err = fmt.Errorf("%s", strings.Join(errs, "; "))
+	case len(errs) > 0:
+		err = fmt.Errorf("%w; %s", limited, strings.Join(errs, "; "))
+	}
+	if len(failed) == len(recipients) {
+		return err
+	}
+	return &partialSendError{Failed: failed, err: err}
+}
+
+// Messages returns the messages whose delivery is still being tracked, or was
+// recently, with their latest delivery status
+func (n *SMSNotifier) Messages() []SMSMessage {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]SMSMessage(nil), n.messages...)
+}
+
+// messagesURL returns the Messages resource URL, optionally for a single message
+func (n *SMSNotifier) messagesURL(sid string) string {
+	api := n.Config.APIURL
+	if api == "" {
+		api = DefaultTwilioAPI
+	}
+	base := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages", strings.TrimRight(api, "/"), n.Config.AccountSID)
+	if sid != "" {
+		return base + "/" + sid + ".json"
+	}
+	return base + ".json"
+}
+
+// twilioMessage is the subset of a Twilio Message resource (or error) that we read
+type twilioMessage struct {
+	SID          string `json:"sid"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message"`
+	Message      string `json:"message"` // set on API errors
+}
+
+// send creates a single message and returns its SID and initial status
+func (n *SMSNotifier) send(to, body string) (SMSMessage, error) {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", n.Config.From)
+	form.Set("Body", body)
+
+	req, err := http.NewRequest(http.MethodPost, n.messagesURL(""), strings.NewReader(form.Encode()))
+	if err != nil {
+		return SMSMessage{}, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	msg, err := n.do(req)
+	if err != nil {
+		return SMSMessage{}, err
+	}
+	return SMSMessage{SID: msg.SID, To: to, Status: msg.Status}, nil
+}
+
+// fetchStatus looks up the current delivery status of a sent message
+func (n *SMSNotifier) fetchStatus(sid string) (twilioMessage, error) {
+	req, err := http.NewRequest(http.MethodGet, n.messagesURL(sid), nil)
+	if err != nil {
+		return twilioMessage{}, fmt.Errorf("failed to build request: %w", err)
+	}
+	return n.do(req)
+}
+
+// do sends an authenticated API request and decodes the message resource
+func (n *SMSNotifier) do(req *http.Request) (twilioMessage, error) {
+	req.SetBasicAuth(n.Config.AccountSID, n.Config.AuthToken.Value())
+
+	resp, err := notifyClient.Do(req)
+	if err != nil {
+		return twilioMessage{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return twilioMessage{}, fmt.Errorf("%w: %s", errRateLimited, resp.Status)
+	}
+
+	var msg twilioMessage
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return twilioMessage{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if msg.Message != "" {
+			return twilioMessage{}, fmt.Errorf("unexpected status: %s: %s", resp.Status, msg.Message)
+		}
+		return twilioMessage{}, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return msg, nil
+}
+
+// trackDelivery polls a message until it reaches a final status, then reports it in the UI
+func (n *SMSNotifier) trackDelivery(sid string) {
+	interval := n.interval()
+	for range smsStatusPolls {
+		time.Sleep(interval)
+
+		msg, err := n.fetchStatus(sid)
+		if err != nil {
+			continue
+		}
+		n.setStatus(sid, msg.Status)
+
+		switch msg.Status {
+		case "delivered":
+			PrintSMSStatus(sid, msg.Status, "")
+			return
+		case "undelivered", "failed":
+			PrintSMSStatus(sid, msg.Status, msg.ErrorMessage)
+			return
+		}
+	}
+}
+
+// interval returns the delay between delivery status checks
+func (n *SMSNotifier) interval() time.Duration {
+	if n.pollInterval == 0 {
+		return 10 * time.Second
+	}
+	return n.pollInterval
+}
+
+// setStatus records the latest delivery status for a message
+func (n *SMSNotifier) setStatus(sid, status string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i := range n.messages {
+		if n.messages[i].SID == sid {
+			n.messages[i].Status = status
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ===================
+// Fake Twilio Messages API
+// ===================
+
+func newFakeTwilio(t *testing.T, finalStatus string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if user != "AC123" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":20003,"message":"Authenticate"}`))
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/2010-04-01/Accounts/AC123/Messages.json":
+			r.ParseForm()
+			mu.Lock()
+			bodies = append(bodies, r.FormValue("To")+"|"+r.FormValue("Body"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"sid":"SM1","status":"queued"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/2010-04-01/Accounts/AC123/Messages/SM1.json":
+			w.Write([]byte(`{"sid":"SM1","status":"` + finalStatus + `"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	return server, &bodies
+}
+
+// ===================
+// SMSNotifier tests
+// ===================
+
+func TestSMSNotifier_SendsAndTracksDelivery(t *testing.T) {
+	server, bodies := newFakeTwilio(t, "delivered")
+	defer server.Close()
+
+	n := &SMSNotifier{
+		Config:       SMSConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15550000000", To: []string{"+15551111111"}, APIURL: server.URL},
+		pollInterval: time.Millisecond,
+	}
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(*bodies) != 1 || !strings.HasPrefix((*bodies)[0], "+15551111111|OPEN SEAT: Intro to Testing") {
+		t.Errorf("unexpected sent messages: %v", *bodies)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if msgs := n.Messages(); len(msgs) == 1 && msgs[0].Status == "delivered" {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Errorf("expected delivered status, got %+v", n.Messages())
+}
+
+func TestSMSNotifier_ForgetsMessagesPastTracking(t *testing.T) {
+	server, _ := newFakeTwilio(t, "delivered")
+	defer server.Close()
+
+	n := &SMSNotifier{
+		Config:       SMSConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15550000000", To: []string{"+15551111111"}, APIURL: server.URL},
+		pollInterval: time.Second, // tracked for six seconds
+	}
+	n.messages = []SMSMessage{
+		{SID: "SM0", Status: "delivered", Sent: time.Now().Add(-time.Hour)},
+		{SID: "SM9", Status: "queued", Sent: time.Now()},
+	}
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345"}); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := n.Messages()
+	if len(msgs) != 2 || msgs[0].SID != "SM9" || msgs[1].SID != "SM1" {
+		t.Errorf("expected only the recent messages kept, got %+v", msgs)
+	}
+}
+
+func TestSMSNotifier_AuthError(t *testing.T) {
+	server, _ := newFakeTwilio(t, "delivered")
+	defer server.Close()
+
+	n := &SMSNotifier{Config: SMSConfig{AccountSID: "AC123", AuthToken: "wrong", From: "+1", To: []string{"+2"}, APIURL: server.URL}}
+	err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345"})
+	if err == nil || !strings.Contains(err.Error(), "Authenticate") {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+}
+
+// partialTwilio is a fake Twilio API that refuses messages to one recipient
+// with the given status until told otherwise
+type partialTwilio struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	refuse string // recipient whose messages are refused, or "" once it recovers
+	status int
+	sentTo []string
+}
+
+func newPartialTwilio(t *testing.T, refuse string, status int) *partialTwilio {
+	t.Helper()
+	p := &partialTwilio{refuse: refuse, status: status}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Write([]byte(`{"sid":"SM1","status":"delivered"}`))
+			return
+		}
+		r.ParseForm()
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if r.FormValue("To") == p.refuse {
+			w.WriteHeader(p.status)
+			w.Write([]byte(`{"code":20429,"message":"Refused"}`))
+			return
+		}
+		p.sentTo = append(p.sentTo, r.FormValue("To"))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM1","status":"queued"}`))
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+// recover lets messages to the refused recipient through
+func (p *partialTwilio) recover() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refuse = ""
+}
+
+// sent lists the recipients texted so far, in order
+func (p *partialTwilio) sent() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return strings.Join(p.sentTo, ",")
+}
+
+// newPartialSMSDispatcher dispatches to two SMS recipients through the fake API
+func newPartialSMSDispatcher(t *testing.T, api *partialTwilio, policy AlertPolicy, now *time.Time, others ...Notifier) *Dispatcher {
+	t.Helper()
+	sms := &SMSNotifier{
+		Config:       SMSConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15550000000", To: []string{"+15551111111", "+15552222222"}, APIURL: api.URL},
+		pollInterval: time.Millisecond,
+	}
+	d := NewDispatcher(append([]Notifier{sms}, others...), policy)
+	d.now = func() time.Time { return *now }
+	d.outbox = openTestOutbox(t, now)
+	return d
+}
+
+func TestDispatcher_PartialSMSOnlyRetriesMissedRecipients(t *testing.T) {
+	api := newPartialTwilio(t, "+15552222222", http.StatusTooManyRequests)
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	push := &countingNotifier{name: "ntfy"}
+	d := newPartialSMSDispatcher(t, api, AlertPolicy{}, &now, push)
+	d.quotas = openTestQuotas(t, map[string]ChannelQuota{"sms": {Fallback: "ntfy"}})
+	d.configure(func(s *dispatchSettings) { s.channels = map[string][]string{"12345": {"sms"}} }) // ntfy only gets it as the fallback
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"})
+	d.Flush()
+	if push.sent != 0 {
+		t.Errorf("fallback sent %d after a partial send, want 0", push.sent)
+	}
+	entries := d.outbox.Entries()
+	if len(entries) != 1 || strings.Join(entries[0].Event.Recipients, ",") != "+15552222222" {
+		t.Fatalf("expected a retry for the missed recipient only, got %+v", entries)
+	}
+
+	api.recover()
+	now = now.Add(rateLimitBackoff + time.Minute)
+	d.RetryDue()
+	if sent := api.sent(); sent != "+15551111111,+15552222222" {
+		t.Errorf("texts went to %v, want each recipient once", sent)
+	}
+}
+
+func TestDispatcher_PartialSMSRetryIgnoresCooldown(t *testing.T) {
+	api := newPartialTwilio(t, "+15552222222", http.StatusInternalServerError)
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	d := newPartialSMSDispatcher(t, api, AlertPolicy{Cooldown: 600}, &now)
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"})
+	d.Flush()
+
+	api.recover()
+	now = now.Add(time.Minute)
+	d.RetryDue()
+	if sent := api.sent(); sent != "+15551111111,+15552222222" {
+		t.Errorf("texts went to %v, want the missed recipient retried inside the cooldown", sent)
+	}
+	if entries := d.outbox.Entries(); len(entries) != 0 {
+		t.Errorf("expected outbox empty after retry, got %+v", entries)
+	}
+}
+
+func TestSMSNotifier_AcceptsOnlyConfiguredCRNs(t *testing.T) {
+	n := &SMSNotifier{Config: SMSConfig{CRNs: []string{"12345"}}}
+
+	if !n.Accepts(Event{Kind: EventSeatOpen, CRN: "12345"}) {
+		t.Error("expected configured CRN to be accepted")
+	}
+	if n.Accepts(Event{Kind: EventSeatOpen, CRN: "67890"}) {
+		t.Error("expected other CRN to be rejected")
+	}
+	if n.Accepts(Event{Kind: EventError, CRN: "12345"}) {
+		t.Error("expected error events to be rejected")
+	}
+}
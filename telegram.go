@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTelegramAPI is the Telegram Bot API endpoint
+const DefaultTelegramAPI = "https://api.telegram.org"
+
+// telegramPollTimeout is how long a getUpdates long-poll waits for new messages
+const telegramPollTimeout = 25 * time.Second
+
+// TelegramConfig configures seat alerts and remote commands via a Telegram bot
+type TelegramConfig struct {
+	Token    Secret `json:"token"`    // Bot token from @BotFather (required)
+	ChatID   string `json:"chatId"`   // Chat to send alerts to and accept commands from (required)
+	APIURL   string `json:"apiUrl"`   // Bot API base URL (optional, for testability) (defaults to api.telegram.org)
+	Commands bool   `json:"commands"` // Listen for /status, /add, /remove, /pause and /resume
+
+	ErrorThreshold int `json:"errorThreshold"` // Consecutive failures before an error is sent (default 3)
+}
+
+// TelegramNotifier sends events to a Telegram chat and optionally accepts commands from it
+type TelegramNotifier struct {
+	Config TelegramConfig
+
+	offset int64 // next update_id to request from getUpdates
+}
+
+func (n *TelegramNotifier) Name() string { return "telegram" }
+
+// Accepts sends a check error once per failure streak, when it reaches the threshold
+func (n *TelegramNotifier) Accepts(ev Event) bool {
+	return ev.Kind != EventError || reachesErrorThreshold(ev, n.Config.ErrorThreshold)
+}
+
+func (n *TelegramNotifier) Notify(ev Event) error {
+	text := ev.Body()
+	switch {
+	case len(ev.Batch) > 0:
+		text = fmt.Sprintf("%s\n%s", ev.Title(), ev.Body())
+	case ev.Kind == EventSeatOpen:
+		text = fmt.Sprintf("%s\n%s\n\nRegister: %s", ev.Title(), ev.Body(), RegistrationURL)
+	}
+	return n.sendMessage(text)
+}
+
+// endpoint returns the Bot API URL for the given method
+func (n *TelegramNotifier) endpoint(method string) string {
+	api := n.Config.APIURL
+	if api == "" {
+		api = DefaultTelegramAPI
+	}
+	return fmt.Sprintf("%s/bot%s/%s", strings.TrimRight(api, "/"), n.Config.Token.Value(), method)
+}
+
+// sendMessage posts text to the configured chat
+func (n *TelegramNotifier) sendMessage(text string) error {
+	if n.Config.Token == "" || n.Config.ChatID == "" {
+		return fmt.Errorf("telegram token and chatId must be set")
+	}
+
+	body, err := json.Marshal(map[string]string{"chat_id": n.Config.ChatID, "text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, n.endpoint("sendMessage"), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return hideSecret(doNotifyRequest(req), n.Config.Token)
+}
+
+// checkBot reports a bot that another profile already listens to for commands.
+// listening maps each listening bot's token to the path of its config, e.g.
+// "profiles[0].telegram".
+func (c TelegramConfig) checkBot(path string, listening map[string]string, errs *ConfigErrors) {
+	if !c.Commands || c.Token == "" {
+		return
+	}
+	if first, ok := listening[c.Token.Value()]; ok {
+		errs.add(path+".token", "is the same bot as %s; each profile listening for commands needs its own bot", first)
+		return
+	}
+	listening[c.Token.Value()] = path
+}
+
+// ===================================
+// Commands
+// ===================================
+
+// telegramUpdate is the subset of a Bot API Update used for commands
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID       int64  `json:"id"`
+			Username string `json:"username"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// getUpdates long-polls the Bot API for new messages
+func (n *TelegramNotifier) getUpdates(ctx context.Context) ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.FormatInt(n.offset, 10))
+	params.Set("timeout", strconv.Itoa(int(telegramPollTimeout.Seconds())))
+	params.Set("allowed_updates", `["message"]`)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint("getUpdates"), strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, hideSecret(fmt.Errorf("failed to create request: %w", err), n.Config.Token)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{Timeout: telegramPollTimeout + 10*time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, hideSecret(fmt.Errorf("request failed: %w", err), n.Config.Token)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool             `json:"ok"`
+		Description string           `json:"description"`
+		Result      []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("telegram: %s", result.Description)
+	}
+
+	for _, u := range result.Result {
+		if u.UpdateID >= n.offset {
+			n.offset = u.UpdateID + 1
+		}
+	}
+	return result.Result, nil
+}
+
+// Listen polls for chat commands until stop is closed.
+// Returns immediately unless commands are enabled in the config.
+func (n *TelegramNotifier) Listen(stop <-chan struct{}, ctl MonitorControl) {
+	if !n.Config.Commands {
+		return
+	}
+	// stopping cancels a long poll in progress
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		updates, err := n.getUpdates(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			PrintNotifyError(n.Name(), err)
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			if u.Message == nil || !n.fromConfiguredChat(u) {
+				continue
+			}
+			reply := handleCommand(u.Message.Text, ctl)
+			if reply == "" {
+				continue
+			}
+			if err := n.sendMessage(reply); err != nil {
+				PrintNotifyError(n.Name(), err)
+			}
+		}
+	}
+}
+
+// fromConfiguredChat reports whether the update came from the configured chat,
+// so strangers who find the bot can't control the monitor
+func (n *TelegramNotifier) fromConfiguredChat(u telegramUpdate) bool {
+	chat := u.Message.Chat
+	if strconv.FormatInt(chat.ID, 10) == n.Config.ChatID {
+		return true
+	}
+	return chat.Username != "" && "@"+chat.Username == n.Config.ChatID
+}
+
+// handleCommand executes a chat command against the monitor and returns the reply text.
+// Returns an empty string for messages that aren't commands.
+func handleCommand(text string, ctl MonitorControl) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	// commands in group chats arrive as /status@BotName
+	cmd, _, _ := strings.Cut(fields[0], "@")
+	args := fields[1:]
+
+	switch cmd {
+	case "/status":
+		return formatStatus(ctl.Status(), ctl.Paused())
+	case "/add":
+		if len(args) != 1 {
+			return "Usage: /add CRN"
+		}
+		course, err := ctl.AddCRN(args[0])
+		if err != nil {
+			return fmt.Sprintf("Could not add %s: %v", args[0], err)
+		}
+		return fmt.Sprintf("Now monitoring %s (%s)", course.CRN, course.Name)
+	case "/remove":
+		if len(args) != 1 {
+			return "Usage: /remove CRN"
+		}
+		if !ctl.RemoveCRN(args[0]) {
+			return fmt.Sprintf("%s is not being monitored", args[0])
+		}
+		return fmt.Sprintf("Stopped monitoring %s", args[0])
+	case "/got", "/missed":
+		if len(args) != 1 {
+			return fmt.Sprintf("Usage: %s CRN", cmd)
+		}
+		registered := cmd == "/got"
+		if err := ctl.Resolve(args[0], registered); err != nil {
+			return err.Error()
+		}
+		if registered {
+			return fmt.Sprintf("Nice! Stopped monitoring %s.", args[0])
+		}
+		return fmt.Sprintf("Re-armed %s; you'll be alerted when it opens again.", args[0])
+	case "/ack":
+		crn := ""
+		if len(args) > 0 {
+			crn = args[0]
+		}
+		switch n := ctl.Acknowledge(crn); n {
+		case 0:
+			return "No alerts are escalating."
+		case 1:
+			return "Acknowledged; escalation stopped."
+		default:
+			return fmt.Sprintf("Acknowledged %d alerts; escalation stopped.", n)
+		}
+	case "/pause":
+		ctl.SetPaused(true)
+		return "Polling paused. Send /resume to continue."
+	case "/resume":
+		ctl.SetPaused(false)
+		return "Polling resumed."
+	default:
+		return "Commands: /status, /add CRN, /remove CRN, /got CRN, /missed CRN, /ack [CRN], /pause, /resume"
+	}
+}
+
+// formatStatus renders the watch list as a chat message
+func formatStatus(courses []CourseStatus, paused bool) string {
+	if len(courses) == 0 {
+		return "No CRNs are being monitored."
+	}
+
+	var b strings.Builder
+	if paused {
+		b.WriteString("Polling is paused.\n")
+	}
+	for _, c := range courses {
+		state := "waiting"
+		switch {
+		case c.Open:
+			state = "OPEN"
+		case c.Found:
+			state = "done"
+		}
+		fmt.Fprintf(&b, "%s  %s — %s\n", c.CRN, c.Name, state)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ===================
+// Fake Telegram Bot API
+// ===================
+
+type fakeBotAPI struct {
+	mu      sync.Mutex
+	updates []map[string]any
+	sent    []string
+}
+
+func (f *fakeBotAPI) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+			var msg struct {
+				ChatID string `json:"chat_id"`
+				Text   string `json:"text"`
+			}
+			json.NewDecoder(r.Body).Decode(&msg)
+			f.sent = append(f.sent, msg.Text)
+			w.Write([]byte(`{"ok":true}`))
+		case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": f.updates})
+			f.updates = nil
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}
+}
+
+func (f *fakeBotAPI) messages() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sent...)
+}
+
+// ===================
+// TelegramNotifier tests
+// ===================
+
+func TestTelegramNotifier_SeatOpen(t *testing.T) {
+	api := &fakeBotAPI{}
+	server := httptest.NewServer(api.handler(t))
+	defer server.Close()
+
+	n := &TelegramNotifier{Config: TelegramConfig{Token: "123:abc", ChatID: "42", APIURL: server.URL}}
+	if err := n.Notify(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Intro to Testing"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent := api.messages()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(sent))
+	}
+	if !strings.Contains(sent[0], "Intro to Testing") || !strings.Contains(sent[0], RegistrationURL) {
+		t.Errorf("message = %q", sent[0])
+	}
+}
+
+func TestTelegramNotifier_ListenHandlesCommands(t *testing.T) {
+	api := &fakeBotAPI{updates: []map[string]any{
+		{"update_id": 1, "message": map[string]any{"text": "/pause", "chat": map[string]any{"id": 42}}},
+		{"update_id": 2, "message": map[string]any{"text": "/remove 12345", "chat": map[string]any{"id": 99}}},
+	}}
+	server := httptest.NewServer(api.handler(t))
+	defer server.Close()
+
+	monitor := NewMonitor(Config{}, []CourseStatus{{CRN: "12345", Name: "Intro to Testing"}})
+	n := &TelegramNotifier{Config: TelegramConfig{Token: "123:abc", ChatID: "42", APIURL: server.URL, Commands: true}}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		n.Listen(stop, monitor)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(api.messages()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(stop)
+	<-done
+
+	if !monitor.Paused() {
+		t.Error("expected /pause from configured chat to pause polling")
+	}
+	if len(monitor.Status()) != 1 {
+		t.Error("expected /remove from another chat to be ignored")
+	}
+	if sent := api.messages(); len(sent) != 1 {
+		t.Errorf("expected 1 reply, got %d: %v", len(sent), sent)
+	}
+}
+
+// ===================
+// handleCommand tests
+// ===================
+
+func TestHandleCommand_AddAndRemove(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<table class="dataentrytable"><tr><td>67890</td><td>001</td><td>Data Structures</td></tr></table>`))
+	}))
+	defer server.Close()
+
+	monitor := NewMonitor(Config{BaseURL: server.URL}, []CourseStatus{{CRN: "12345", Name: "Intro to Testing"}})
+
+	if reply := handleCommand("/add 67890", monitor); !strings.Contains(reply, "Data Structures") {
+		t.Errorf("/add reply = %q", reply)
+	}
+	if got := len(monitor.Status()); got != 2 {
+		t.Fatalf("expected 2 courses after /add, got %d", got)
+	}
+	if reply := handleCommand("/add 67890", monitor); !strings.Contains(reply, "already") {
+		t.Errorf("duplicate /add reply = %q", reply)
+	}
+
+	handleCommand("/remove@OpenSeatBot 12345", monitor)
+	status := monitor.Status()
+	if len(status) != 1 || status[0].CRN != "67890" {
+		t.Errorf("unexpected status after /remove: %+v", status)
+	}
+}
+
+func TestMonitor_AddCRN(t *testing.T) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		time.Sleep(20 * time.Millisecond) // let concurrent adds overlap
+		w.Write([]byte(`<table class="dataentrytable"><tr><td>67890</td><td>001</td><td>Data Structures</td></tr></table>`))
+	}))
+	defer server.Close()
+	monitor := NewMonitor(Config{BaseURL: server.URL}, nil)
+
+	if _, err := monitor.AddCRN("1"); err == nil || !strings.Contains(err.Error(), "not a CRN") {
+		t.Errorf("expected a format error, got %v", err)
+	}
+	if lookups.Load() != 0 {
+		t.Error("a malformed CRN shouldn't be looked up")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := monitor.AddCRN("67890")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	var failed int
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if got := len(monitor.Status()); got != 1 || failed != 1 {
+		t.Errorf("concurrent adds left %d courses with %d errors, want 1 and 1", got, failed)
+	}
+}
+
+func TestHandleCommand_Status(t *testing.T) {
+	monitor := NewMonitor(Config{}, []CourseStatus{
+		{CRN: "12345", Name: "Intro to Testing", Found: true, Open: true},
+		{CRN: "67890", Name: "Data Structures"},
+		{CRN: "11111", Name: "Linear Algebra", Found: true},
+	})
+
+	reply := handleCommand("/status", monitor)
+	if !strings.Contains(reply, "12345  Intro to Testing — OPEN") {
+		t.Errorf("status reply = %q", reply)
+	}
+	if !strings.Contains(reply, "67890  Data Structures — waiting") {
+		t.Errorf("status reply = %q", reply)
+	}
+	if !strings.Contains(reply, "11111  Linear Algebra — done") {
+		t.Errorf("status reply = %q", reply)
+	}
+}
+
+func TestHandleCommand_IgnoresPlainText(t *testing.T) {
+	monitor := NewMonitor(Config{}, nil)
+	if reply := handleCommand("hello there", monitor); reply != "" {
+		t.Errorf("expected no reply, got %q", reply)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Template parts, each read from a file named <event>.<part>
+const (
+	partTitle = "title"
+	partText  = "txt"
+	partHTML  = "html"
+)
+
+// TemplateData is what notification templates can refer to
+type TemplateData struct {
+	Event           Event          // the event being sent
+	Kind            string         // event kind, e.g. "seat_open"
+	Channel         string         // channel the message is rendered for
+	Title           string         // built-in headline
+	Body            string         // built-in plain-text body
+	Section         *Section       // timetable details for a single opening, when known
+	Openings        []Event        // every opening covered, for seat-open events
+	RegistrationURL string         // VT registration page
+	AckURL          string         // acknowledgement link for escalating openings
+	Courses         []CourseStatus // watch list with per-CRN statistics
+	Time            time.Time      // when the event happened
+}
+
+// defaultHTML renders emails when no HTML template is configured
+const defaultHTML = `<!DOCTYPE html>
+<html><body style="font-family: sans-serif">
+<h2>{{.Title}}</h2>
+{{- if .Openings}}
+<table cellpadding="6" style="border-collapse: collapse">
+<tr><th align="left">Course</th><th align="left">CRN</th><th align="left">Seats</th><th align="left">Meets</th><th align="left">Instructor</th></tr>
+{{- range .Openings}}
+<tr>
+<td>{{with .Section}}{{.Course}} {{end}}{{.Name}}</td>
+<td>{{.CRN}}</td>
+<td>{{with .Section}}{{if ge .Seats 0}}{{.Seats}}{{if gt .Capacity 0}} of {{.Capacity}}{{end}}{{end}}{{end}}</td>
+<td>{{with .Section}}{{.MeetingTime}}{{end}}</td>
+<td>{{with .Section}}{{.Instructor}}{{end}}</td>
+</tr>
+{{- end}}
+</table>
+<p><a href="{{.RegistrationURL}}">Register now</a></p>
+{{- if .AckURL}}
+<p><a href="{{.AckURL}}">Acknowledge this alert</a></p>
+{{- end}}
+{{- else}}
+<pre>{{.Body}}</pre>
+{{- end}}
+</body></html>
+`
+
+// Templates holds user overrides for notification content. Files are looked up
+// as <dir>/<channel>/<event>.<part>, then <dir>/<event>.<part>, where part is
+// "title", "txt" or "html"; anything missing uses the built-in content.
+type Templates struct {
+	text map[string]*texttemplate.Template // keyed by templateKey
+	html map[string]*htmltemplate.Template
+}
+
+// templateKey identifies a template for a channel ("" for every channel), event kind and part
+func templateKey(channel, kind, part string) string {
+	return channel + "/" + kind + "." + part
+}
+
+// LoadTemplates parses every template in dir. An empty dir loads none, so only
+// the built-in content is used.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{text: map[string]*texttemplate.Template{}, html: map[string]*htmltemplate.Template{}}
+
+	builtin, err := htmltemplate.New("default.html").Parse(defaultHTML)
+	if err != nil {
+		return nil, err
+	}
+	t.html[templateKey("", "", partHTML)] = builtin
+
+	if dir == "" {
+		return t, nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("templates directory: %w", err)
+	}
+
+	err = filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		channel, file := filepath.Split(filepath.ToSlash(rel))
+		channel = strings.TrimSuffix(channel, "/")
+		if strings.Contains(channel, "/") {
+			return nil // only one level of channel directories
+		}
+		kind, part, ok := strings.Cut(file, ".")
+		if !ok || (part != partTitle && part != partText && part != partHTML) {
+			return nil
+		}
+		if err := (new(EventKind)).UnmarshalText([]byte(kind)); err != nil {
+			return fmt.Errorf("template %s: %w", rel, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		key := templateKey(channel, kind, part)
+		if part == partHTML {
+			tmpl, err := htmltemplate.New(rel).Parse(string(data))
+			if err != nil {
+				return fmt.Errorf("template %s: %w", rel, err)
+			}
+			t.html[key] = tmpl
+			return nil
+		}
+		tmpl, err := texttemplate.New(rel).Parse(string(data))
+		if err != nil {
+			return fmt.Errorf("template %s: %w", rel, err)
+		}
+		t.text[key] = tmpl
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Apply renders the channel's templates for ev and returns the event carrying
+// the rendered title, text and HTML. Parts without a template keep the built-in content.
+func (t *Templates) Apply(channel string, ev Event, courses []CourseStatus) (Event, error) {
+	data := newTemplateData(channel, ev, courses)
+	kind := ev.Kind.String()
+
+	var errs []error
+	if tmpl := t.textTemplate(channel, kind, partTitle); tmpl != nil {
+		title, err := renderText(tmpl, data)
+		errs = append(errs, err)
+		ev.title = strings.TrimSpace(title)
+	}
+	if tmpl := t.textTemplate(channel, kind, partText); tmpl != nil {
+		text, err := renderText(tmpl, data)
+		errs = append(errs, err)
+		ev.text = text
+	}
+
+	// the HTML template sees the customized title and text
+	data.Title, data.Body = ev.Title(), ev.Body()
+	if tmpl := t.htmlTemplate(channel, kind); tmpl != nil {
+		var b strings.Builder
+		errs = append(errs, tmpl.Execute(&b, data))
+		ev.html = b.String()
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return ev, fmt.Errorf("%s template: %w", channel, err)
+	}
+	return ev, nil
+}
+
+// textTemplate returns the most specific text template for a part, or nil
+func (t *Templates) textTemplate(channel, kind, part string) *texttemplate.Template {
+	if tmpl, ok := t.text[templateKey(channel, kind, part)]; ok {
+		return tmpl
+	}
+	return t.text[templateKey("", kind, part)]
+}
+
+// htmlTemplate returns the most specific HTML template, falling back to the built-in one
+func (t *Templates) htmlTemplate(channel, kind string) *htmltemplate.Template {
+	for _, key := range []string{templateKey(channel, kind, partHTML), templateKey("", kind, partHTML)} {
+		if tmpl, ok := t.html[key]; ok {
+			return tmpl
+		}
+	}
+	return t.html[templateKey("", "", partHTML)]
+}
+
+// renderText executes a text template to a string
+func renderText(tmpl *texttemplate.Template, data TemplateData) (string, error) {
+	var b strings.Builder
+	err := tmpl.Execute(&b, data)
+	return b.String(), err
+}
+
+// newTemplateData collects everything a template can refer to for an event
+func newTemplateData(channel string, ev Event, courses []CourseStatus) TemplateData {
+	data := TemplateData{
+		Event:           ev,
+		Kind:            ev.Kind.String(),
+		Channel:         channel,
+		Title:           ev.Title(),
+		Body:            ev.Body(),
+		Section:         ev.Section,
+		RegistrationURL: RegistrationURL,
+		AckURL:          ev.AckURL,
+		Courses:         courses,
+		Time:            ev.Time,
+	}
+	if ev.Kind == EventSeatOpen {
+		data.Openings = ev.Batch
+		if len(ev.Batch) == 0 {
+			data.Openings = []Event{ev}
+		}
+	}
+	return data
+}
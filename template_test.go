@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ===================
+// Template tests
+// ===================
+
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestTemplates_BuiltInHTMLListsSections(t *testing.T) {
+	tmpl, err := LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := Event{Kind: EventSeatOpen, CRN: "12345", Name: "Data Structures",
+		Section: &Section{Course: "CS-3114", Seats: 2, Capacity: 40, Days: "MWF", Begin: "10:10AM", End: "11:00AM", Instructor: "A <Smith>"}}
+
+	out, err := tmpl.Apply("email", ev, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"CS-3114 Data Structures", "2 of 40", "MWF 10:10AM-11:00AM", "A &lt;Smith&gt;", RegistrationURL} {
+		if !strings.Contains(out.HTML(), want) {
+			t.Errorf("HTML missing %q:\n%s", want, out.HTML())
+		}
+	}
+	if out.Title() != ev.Title() || out.Body() != ev.Body() {
+		t.Error("built-in title and text should be unchanged without templates")
+	}
+}
+
+func TestTemplates_ChannelOverridesGeneric(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"seat_open.title":     "Open: {{.Event.Name}}",
+		"seat_open.txt":       "{{.Event.CRN}} has {{.Section.Seats}} seats. {{.RegistrationURL}}",
+		"sms/seat_open.txt":   "{{.Event.CRN}} open",
+		"digest.txt":          "{{len .Courses}} courses watched",
+		"ntfy/notes.markdown": "ignored",
+	})
+	tmpl, err := LoadTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := Event{Kind: EventSeatOpen, CRN: "12345", Name: "Data Structures", Section: &Section{Seats: 3}}
+
+	push, err := tmpl.Apply("ntfy", ev, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if push.Title() != "Open: Data Structures" || push.Body() != "12345 has 3 seats. "+RegistrationURL {
+		t.Errorf("generic template not applied: %q / %q", push.Title(), push.Body())
+	}
+
+	sms, _ := tmpl.Apply("sms", ev, nil)
+	if sms.Body() != "12345 open" || sms.Title() != "Open: Data Structures" {
+		t.Errorf("channel template not applied: %q / %q", sms.Title(), sms.Body())
+	}
+
+	digest, _ := tmpl.Apply("email", Event{Kind: EventDigest}, []CourseStatus{{CRN: "1"}, {CRN: "2"}})
+	if digest.Body() != "2 courses watched" {
+		t.Errorf("digest body = %q", digest.Body())
+	}
+}
+
+func TestLoadTemplates_Errors(t *testing.T) {
+	if _, err := LoadTemplates(writeTemplates(t, map[string]string{"seat_open.txt": "{{.Event.CRN"})); err == nil {
+		t.Error("expected parse error")
+	}
+	if _, err := LoadTemplates(writeTemplates(t, map[string]string{"seat_opened.txt": "x"})); err == nil {
+		t.Error("expected error for unknown event kind")
+	}
+	if _, err := LoadTemplates(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+// htmlMockSender records multipart sends
+type htmlMockSender struct {
+	MockEmailSender
+	text, html string
+}
+
+func (m *htmlMockSender) SendHTML(to, subject, text, html string) error {
+	m.text, m.html = text, html
+	return nil
+}
+
+func TestDispatcher_SendsMultipartEmail(t *testing.T) {
+	sender := &htmlMockSender{}
+	d := NewDispatcher([]Notifier{&EmailNotifier{Sender: sender, To: "me@example.com"}}, AlertPolicy{})
+	d.configure(func(s *dispatchSettings) { s.templates, _ = LoadTemplates("") })
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345", Name: "Data Structures"})
+	d.Flush()
+
+	if !strings.Contains(sender.text, "OPEN SEAT: Data Structures") {
+		t.Errorf("text part = %q", sender.text)
+	}
+	if !strings.Contains(sender.html, "<table") {
+		t.Errorf("html part = %q", sender.html)
+	}
+	if len(sender.Sent) != 0 {
+		t.Error("expected SendHTML to be used instead of Send")
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Term is a timetable term code, YYYYMM, where the month picks the term:
+// 01 Spring, 06 Summer I, 07 Summer II and 09 Fall
+type Term string
+
+// termSeasons maps each term code month to its name, in calendar order
+var termSeasons = []struct {
+	month string
+	name  string
+}{
+	{"01", "Spring"},
+	{"06", "Summer I"},
+	{"07", "Summer II"},
+	{"09", "Fall"},
+}
+
+// termNamePattern matches a season and year such as "Spring 2026", "fall25",
+// "Summer I 2026" or "summer-2-26"
+var termNamePattern = regexp.MustCompile(`^(spring|fall|autumn|summer[\s-]*(?:ii|i|2|1)|summer)[\s-]*'?([0-9]{2}|[0-9]{4})$`)
+
+// ParseTerm reads a term code ("202601"), a season and year ("Spring 2026",
+// "fall25", "Summer I 2026"), or "current" or "next" relative to now
+func ParseTerm(s string, now time.Time) (Term, error) {
+	text := strings.ToLower(strings.Join(strings.Fields(s), " "))
+	switch text {
+	case "current":
+		return CurrentTerm(now), nil
+	case "next":
+		return CurrentTerm(now).Next(), nil
+	}
+	if termPattern.MatchString(text) {
+		return Term(text), nil
+	}
+
+	m := termNamePattern.FindStringSubmatch(text)
+	if m == nil {
+		return "", fmt.Errorf("%q is not a term code; use a term like \"Spring 2026\", \"fall26\" or \"Summer I 2026\", \"current\", \"next\", or a code like 202601", s)
+	}
+	year, _ := strconv.Atoi(m[2])
+	if year < 100 {
+		year += 2000
+	}
+	var month string
+	switch season := strings.Join(strings.FieldsFunc(m[1], func(r rune) bool { return r == ' ' || r == '-' }), ""); season {
+	case "spring":
+		month = "01"
+	case "summeri", "summer1":
+		month = "06"
+	case "summerii", "summer2":
+		month = "07"
+	case "fall", "autumn":
+		month = "09"
+	default:
+		return "", fmt.Errorf("%q has two summer terms; say \"Summer I %d\" or \"Summer II %d\"", s, year, year)
+	}
+	return Term(fmt.Sprintf("%04d%s", year, month)), nil
+}
+
+// CurrentTerm returns the term in session on now's date: Spring through May,
+// Summer I in June, Summer II in July, and Fall from August
+func CurrentTerm(now time.Time) Term {
+	month := "09"
+	switch m := now.Month(); {
+	case m <= time.May:
+		month = "01"
+	case m == time.June:
+		month = "06"
+	case m == time.July:
+		month = "07"
+	}
+	return Term(fmt.Sprintf("%04d%s", now.Year(), month))
+}
+
+// Next returns the term after t, wrapping from Fall to the next Spring
+func (t Term) Next() Term {
+	year, month, ok := t.split()
+	if !ok {
+		return t
+	}
+	for i, s := range termSeasons {
+		if s.month != month {
+			continue
+		}
+		if i == len(termSeasons)-1 {
+			return Term(fmt.Sprintf("%04d%s", year+1, termSeasons[0].month))
+		}
+		return Term(fmt.Sprintf("%04d%s", year, termSeasons[i+1].month))
+	}
+	return t
+}
+
+// String returns the term's name, e.g. "Summer I 2026", or the raw value if
+// it isn't a term code
+func (t Term) String() string {
+	year, month, ok := t.split()
+	if !ok {
+		return string(t)
+	}
+	for _, s := range termSeasons {
+		if s.month == month {
+			return fmt.Sprintf("%s %d", s.name, year)
+		}
+	}
+	return string(t)
+}
+
+// split returns the year and month of a valid term code
+func (t Term) split() (int, string, bool) {
+	if !termPattern.MatchString(string(t)) {
+		return 0, "", false
+	}
+	year, _ := strconv.Atoi(string(t[:4]))
+	return year, string(t[4:]), true
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// Term parsing tests
+// ===================
+
+func TestParseTerm(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		input string
+		want  Term
+	}{
+		{"202601", "202601"},
+		{"Spring 2026", "202601"},
+		{"fall25", "202509"},
+		{"Fall '26", "202609"},
+		{"Autumn 2026", "202609"},
+		{"Summer I 2026", "202606"},
+		{"summer ii 2026", "202607"},
+		{"Summer-2-26", "202607"},
+		{"summer1 2027", "202706"},
+		{"current", "202609"},
+		{"Next", "202701"},
+	}
+	for _, tt := range tests {
+		got, err := ParseTerm(tt.input, now)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseTerm(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "2026-01", "202603", "spring", "Winter 2026", "Summer 2026"} {
+		if _, err := ParseTerm(bad, now); err == nil {
+			t.Errorf("ParseTerm(%q) should fail", bad)
+		}
+	}
+	if _, err := ParseTerm("summer 26", now); err == nil || !strings.Contains(err.Error(), `"Summer I 2026" or "Summer II 2026"`) {
+		t.Errorf("expected a hint about the two summer terms, got %v", err)
+	}
+}
+
+func TestCurrentAndNextTerm(t *testing.T) {
+	tests := []struct {
+		date          string
+		current, next Term
+	}{
+		{"2026-01-12", "202601", "202606"},
+		{"2026-05-30", "202601", "202606"},
+		{"2026-06-15", "202606", "202607"},
+		{"2026-07-20", "202607", "202609"},
+		{"2026-08-01", "202609", "202701"},
+		{"2026-12-31", "202609", "202701"},
+	}
+	for _, tt := range tests {
+		now, _ := time.Parse(time.DateOnly, tt.date)
+		if got := CurrentTerm(now); got != tt.current {
+			t.Errorf("%s: current = %s, want %s", tt.date, got, tt.current)
+		}
+		if got := CurrentTerm(now).Next(); got != tt.next {
+			t.Errorf("%s: next = %s, want %s", tt.date, got, tt.next)
+		}
+	}
+}
+
+func TestTerm_String(t *testing.T) {
+	for term, want := range map[Term]string{"202601": "Spring 2026", "202606": "Summer I 2026", "202607": "Summer II 2026", "202509": "Fall 2025", "bogus": "bogus"} {
+		if got := term.String(); got != want {
+			t.Errorf("%q.String() = %q, want %q", string(term), got, want)
+		}
+	}
+}
+
+func TestLoadConfig_TermNames(t *testing.T) {
+	path := createTempConfig(t, `{"crns": ["12345"], "term": "Fall 2026"}`)
+
+	cfg, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Term != "202609" {
+		t.Errorf("term = %q, want the code 202609", string(cfg.Term))
+	}
+
+	cfg, err = loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(map[string]string{"OPENSEAT_TERM": "next"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Term != CurrentTerm(time.Now()).Next() {
+		t.Errorf("term = %q, want the next term", string(cfg.Term))
+	}
+}
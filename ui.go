@@ -89,7 +89,7 @@ func boxLine(color string, content string) string {
 }
 
 // PrintConfigBox displays the configuration summary in a styled box
-func PrintConfigBox(crnCount int, email string, interval int, term string) {
+func PrintConfigBox(crnCount int, email string, interval int, term Term) {
 	fmt.Println(boxTop(VTMaroon))
 	fmt.Println(boxLine(VTMaroon, fmt.Sprintf("%s%s  Monitoring %s%d CRNs%s", VTOrange, IconTarget, BoldWhite, crnCount, Reset)))
 	if email != "" {
@@ -100,6 +100,37 @@ func PrintConfigBox(crnCount int, email string, interval int, term string) {
 	fmt.Println()
 }
 
+// PrintDotenvSources lists which .env files supplied which variables. Values are never shown.
+func PrintDotenvSources(sources []DotenvSource) {
+	if len(sources) == 0 {
+		return
+	}
+	for _, src := range sources {
+		if len(src.Loaded) > 0 {
+			fmt.Printf("  %s%s%s %sLoaded %s from %s (values hidden)%s\n", Green, IconCheck, Reset, Dim, strings.Join(src.Loaded, ", "), src.Path, Reset)
+		}
+		if len(src.Skipped) > 0 {
+			fmt.Printf("  %s%s%s %sIgnored %s from %s (already set)%s\n", Yellow, IconX, Reset, Dim, strings.Join(src.Skipped, ", "), src.Path, Reset)
+		}
+	}
+	fmt.Println()
+}
+
+// PrintProfiles lists each profile with its CRNs when the config has more than one
+func PrintProfiles(profiles []profile) {
+	if len(profiles) < 2 {
+		return
+	}
+	for _, p := range profiles {
+		crns := "no CRNs of its own"
+		if len(p.Config.CRNs) > 0 {
+			crns = strings.Join(p.Config.CRNs.CRNs(), ", ")
+		}
+		fmt.Printf("  %s%s%s %sProfile %s: %s%s\n", Cyan, IconBell, Reset, Dim, p.Name, crns, Reset)
+	}
+	fmt.Println()
+}
+
 // PrintFetchingHeader displays the "Fetching course information" message
 func PrintFetchingHeader() {
 	fmt.Printf("%s%s  Fetching course information...%s\n\n", Dim, IconSearch, Reset)
@@ -115,6 +146,36 @@ func PrintCourseNotFound(crn string) {
 	fmt.Printf("  %s%s%s %s%s%s: %snot found, skipping%s\n", Red, IconX, Reset, Dim, crn, Reset, Red, Reset)
 }
 
+// PrintCourseRemoved displays a course dropped from the watch list
+func PrintCourseRemoved(crn string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %s%s%s: %sno longer monitored%s\n", Yellow, IconX, Reset, Dim, crn, Reset, Dim, Reset)
+}
+
+// PrintWatchExpired displays a CRN that stopped being monitored at its expiry date
+func PrintWatchExpired(crn string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %s%s%s: %sexpired, no longer monitored%s\n", Yellow, IconClock, Reset, Dim, crn, Reset, Dim, Reset)
+}
+
+// PrintConfigReloaded displays the settings a config reload changed
+func PrintConfigReloaded(path string, diff ConfigDiff) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sReloaded %s%s\n", Green, IconCheck, Reset, Dim, path, Reset)
+	for _, c := range diff.Changes {
+		fmt.Printf("    %s%s%s %s%s%s\n", VTOrange, IconArrow, Reset, Dim, describeChange(c), Reset)
+	}
+	for _, section := range diff.Restart {
+		fmt.Printf("    %s%s%s %s%s changes apply after a restart%s\n", Yellow, IconClock, Reset, Dim, section, Reset)
+	}
+}
+
+// PrintConfigReloadError displays a config edit that was rejected; the previous config stays in use
+func PrintConfigReloadError(path string, err error) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sIgnoring changes to %s, keeping the previous config: %v%s\n", Red, IconX, Reset, Dim, path, err, Reset)
+}
+
 // PrintDivider displays a horizontal divider line
 func PrintDivider() {
 	fmt.Printf("\n%s────────────────────────────────────────────────────%s\n\n", VTMaroon, Reset)
@@ -143,11 +204,98 @@ func PrintSeatAvailable(name, crn string) {
 	fmt.Println(boxBottom(Green))
 }
 
+// PrintSuppressed displays why an observation or notification didn't produce an alert
+func PrintSuppressed(crn, reason string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %s%s%s %s%s%s\n", Yellow, IconClock, Reset, VTOrange, crn, Reset, Dim, reason, Reset)
+}
+
+// PrintSeatClosed displays a notice that a previously open section filled up
+func PrintSeatClosed(name, crn string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %s%s%s %s▸%s %s %sseat taken, watching for the next opening%s\n",
+		Yellow, IconX, Reset, VTOrange, crn, Reset, Dim, Reset, name, Dim, Reset)
+}
+
+// PrintEscalated displays an escalation step sent for an unacknowledged opening
+func PrintEscalated(crn, channel string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %s%s%s %sNot acknowledged, escalating to %s%s\n", Yellow, IconClock, Reset, VTOrange, crn, Reset, Dim, channel, Reset)
+}
+
+// PrintAcknowledged displays an acknowledgement that stopped an escalation
+func PrintAcknowledged(crn, via string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sAlert for CRN %s acknowledged via %s%s\n", Green, IconCheck, Reset, Dim, crn, via, Reset)
+}
+
+// PrintQuotaWarning displays a channel nearing or reaching its message quota
+func PrintQuotaWarning(channel, period string, used, limit int) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %s%s has used %d of its %d %s messages%s\n", Yellow, IconX, Reset, Dim, channel, used, limit, period, Reset)
+}
+
+// PrintQuotaFallback displays a message that a channel's quota or rate limit kept from sending
+func PrintQuotaFallback(channel, reason, fallback string) {
+	ClearLine()
+	if fallback == "" {
+		fmt.Printf("  %s%s%s %s%s not sent (%s), no fallback channel%s\n", Red, IconX, Reset, Dim, channel, reason, Reset)
+		return
+	}
+	fmt.Printf("  %s%s%s %s%s %s, falling back to %s%s\n", Yellow, IconClock, Reset, Dim, channel, reason, fallback, Reset)
+}
+
+// PrintCommandReply displays the response to a command typed into the terminal
+func PrintCommandReply(reply string) {
+	if reply == "" {
+		return
+	}
+	ClearLine()
+	for _, line := range strings.Split(reply, "\n") {
+		fmt.Printf("  %s%s%s %s\n", VTOrange, IconArrow, Reset, line)
+	}
+}
+
 // PrintEmailSent displays the email notification confirmation
 func PrintEmailSent(email string) {
 	fmt.Printf("  %s%s%s %sNotification sent to %s%s\n\n", VTOrange, IconEmail, Reset, Dim, email, Reset)
 }
 
+// PrintDigestSent displays a confirmation that a scheduled digest went out
+func PrintDigestSent(schedule string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %s%s digest sent%s\n", VTOrange, IconCalendar, Reset, Dim, schedule, Reset)
+}
+
+// PrintNotificationSent displays a confirmation for a non-email notification channel
+func PrintNotificationSent(channel string) {
+	fmt.Printf("  %s%s%s %sNotification sent via %s%s\n\n", VTOrange, IconBell, Reset, Dim, channel, Reset)
+}
+
+// PrintRetrySent displays a notification delivered from the outbox after an earlier failure
+func PrintRetrySent(channel, title string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sRetried %s: delivered \"%s\"%s\n", Green, IconCheck, Reset, Dim, channel, title, Reset)
+}
+
+// PrintRetryFailed displays an outbox retry that failed again
+func PrintRetryFailed(channel string, attempt int, err error) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sRetry #%d via %s failed: %v%s\n", Yellow, IconX, Reset, Dim, attempt, channel, err, Reset)
+}
+
+// PrintOutboxError displays a failure to read or write the notification outbox
+func PrintOutboxError(err error) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sOutbox: %v%s\n", Red, IconX, Reset, Dim, err, Reset)
+}
+
+// PrintNotifyError displays a notification channel delivery failure
+func PrintNotifyError(channel string, err error) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %s%s notification failed: %v%s\n", Red, IconX, Reset, Dim, channel, err, Reset)
+}
+
 // PrintWaitingStatus displays the waiting status with spinner
 func PrintWaitingStatus(spinnerIdx, attempt, found, total int, timeLeft, checkTime string) {
 	fmt.Printf("\r%s%s%s %sAttempt #%d%s %s│%s Found: %s%d%s/%s%d%s %s│%s Next: %s%s%s %s[%s]%s          ",
@@ -161,6 +309,59 @@ func PrintWaitingStatus(spinnerIdx, attempt, found, total int, timeLeft, checkTi
 		Dim, checkTime, Reset)
 }
 
+// PrintSMSStatus displays the final delivery status of a text message
+func PrintSMSStatus(sid, status, detail string) {
+	ClearLine()
+	color, icon := Green, IconCheck
+	if status != "delivered" {
+		color, icon = Red, IconX
+	}
+	if detail != "" {
+		status = fmt.Sprintf("%s (%s)", status, detail)
+	}
+	fmt.Printf("  %s%s%s %sSMS %s %s%s\n", color, icon, Reset, Dim, sid, status, Reset)
+}
+
+// PrintHookOutput displays stderr from an exec hook that exited successfully
+func PrintHookOutput(event, line string) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sexec hook (%s): %s%s\n", VTOrange, IconArrow, Reset, Dim, event, line, Reset)
+}
+
+// PrintPausedStatus displays the waiting status while polling is paused
+func PrintPausedStatus(spinnerIdx, found, total int) {
+	fmt.Printf("\r%s%s%s %sPaused%s %s│%s Found: %s%d%s/%s%d%s %s│%s Send /resume to continue          ",
+		Yellow, Spinner[spinnerIdx%len(Spinner)], Reset,
+		BoldYellow, Reset,
+		Dim, Reset,
+		Green, found, Reset,
+		Dim, total, Reset,
+		Dim, Reset)
+}
+
+// PrintUnhealthy displays an alert that every recent check has failed
+func PrintUnhealthy(failures int) {
+	ClearLine()
+	fmt.Printf("\n%s%s  %d checks in a row have failed - sending health alert%s\n", BoldRed, IconBell, failures, Reset)
+}
+
+// PrintHealthRecovered displays a notice that checks are succeeding again after an outage
+func PrintHealthRecovered() {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sChecks are succeeding again%s\n", Green, IconCheck, Reset, Dim, Reset)
+}
+
+// PrintHeartbeatError displays a failed heartbeat ping
+func PrintHeartbeatError(err error) {
+	ClearLine()
+	fmt.Printf("  %s%s%s %sHeartbeat ping failed: %v%s\n", Red, IconX, Reset, Dim, err, Reset)
+}
+
+// PrintInterrupted displays the message shown when the monitor is stopped by a signal
+func PrintInterrupted() {
+	fmt.Printf("\n%s%s  Interrupted, exiting...%s\n", BoldVTOrange, IconX, Reset)
+}
+
 // PrintAllCoursesFound displays the completion message
 func PrintAllCoursesFound() {
 	fmt.Printf("\n%s%s  All courses found! Exiting...%s\n", BoldVTOrange, IconCheck, Reset)
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minCheckInterval is the shortest allowed time between checks, in seconds,
+// so the timetable server isn't hammered
+const minCheckInterval = 10
+
+var (
+	crnPattern    = regexp.MustCompile(`^[0-9]{5}$`)
+	termPattern   = regexp.MustCompile(`^[0-9]{4}(01|06|07|09)$`)
+	campusPattern = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// FieldError is a problem with one config value, located by its JSON path
+type FieldError struct {
+	Path    string // e.g. "crns[1]" or "ntfy.topic"
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ConfigErrors collects every problem found in a config so they can be fixed in one pass
+type ConfigErrors []FieldError
+
+func (e ConfigErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems:", len(e))
+	for _, fe := range e {
+		b.WriteString("\n  - " + fe.Error())
+	}
+	return b.String()
+}
+
+// add records a problem at path
+func (e *ConfigErrors) add(path, format string, args ...any) {
+	*e = append(*e, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// outside returns the problems that aren't at or under a path in found, so a
+// value already reported as undecodable isn't reported again as empty
+func (e ConfigErrors) outside(found ConfigErrors) ConfigErrors {
+	var kept ConfigErrors
+	for _, fe := range e {
+		covered := slices.ContainsFunc(found, func(f FieldError) bool {
+			return f.Path != "" && (fe.Path == f.Path || strings.HasPrefix(fe.Path, f.Path+".") || strings.HasPrefix(fe.Path, f.Path+"["))
+		})
+		if !covered {
+			kept = append(kept, fe)
+		}
+	}
+	return kept
+}
+
+// decodeConfig parses a config file into cfg. Unknown keys and wrongly typed
+// values are returned as field errors, every one of them rather than just the
+// first; malformed JSON is a plain error.
+func decodeConfig(data []byte, cfg *Config) (ConfigErrors, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			line, col := position(data, syntax.Offset)
+			return nil, fmt.Errorf("invalid JSON at line %d, column %d: %v", line, col, err)
+		}
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	// unquoted numbers become strings where strings belong, then plain CRN
+	// strings become objects so both forms decode into Watch
+	stringifyNumbers(raw, reflect.TypeOf(Config{}))
+	expandWatches(raw)
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs ConfigErrors
+	checkFields(raw, reflect.TypeOf(Config{}), "", &errs)
+
+	// encoding/json keeps decoding past a wrongly typed value, which checkFields
+	// has already reported along with any others
+	if err := json.Unmarshal(data, cfg); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return nil, err
+		}
+		if len(errs) == 0 {
+			errs.add(fieldPath(typeErr.Field), "expected %s, got %s", describeType(typeErr.Type), describeValue(typeErr.Value))
+		}
+	}
+	return errs, nil
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (line, col int) {
+	line, col = 1, 1
+	for _, c := range data[:min(int(offset), len(data))] {
+		if c == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// fieldPath converts encoding/json's field path ("crns.1.interval") to the
+// form used in messages ("crns[1].interval")
+func fieldPath(field string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(field, ".") {
+		switch {
+		case part != "" && strings.Trim(part, "0123456789") == "":
+			b.WriteString("[" + part + "]")
+		case i > 0:
+			b.WriteString("." + part)
+		default:
+			b.WriteString(part)
+		}
+	}
+	return b.String()
+}
+
+// describeType names a Go type the way a config author would think of it
+func describeType(t reflect.Type) string {
+	if t == reflect.TypeOf(Watch{}) {
+		return "a CRN or an object"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Int:
+		return "a whole number"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a list"
+	default:
+		return "an object"
+	}
+}
+
+// describeValue shortens encoding/json's description of a value that didn't
+// fit: a number is shown as written ("30.5"), anything else by its kind ("string")
+func describeValue(value string) string {
+	return strings.TrimPrefix(value, "number ")
+}
+
+// checkFields reports keys in raw that don't match a JSON field of t, and
+// values that can't be decoded into their field's type
+func checkFields(raw any, t reflect.Type, path string, errs *ConfigErrors) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch value := raw.(type) {
+	case nil:
+		return // null leaves the field unset
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Map:
+			for _, key := range sortedKeys(value) {
+				checkFields(value[key], t.Elem(), joinPath(path, key), errs)
+			}
+			return
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for _, key := range sortedKeys(value) {
+				field, ok := fields[key]
+				if !ok {
+					errs.add(joinPath(path, key), "unknown field%s", suggestField(key, fields))
+					continue
+				}
+				checkFields(value[key], field.Type, joinPath(path, key), errs)
+			}
+			return
+		}
+	case []any:
+		if t.Kind() == reflect.Slice {
+			for i, item := range value {
+				checkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+			return
+		}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	if err := json.Unmarshal(data, reflect.New(t).Interface()); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			errs.add(path, "expected %s, got %s", describeType(t), describeValue(typeErr.Value))
+		} else {
+			errs.add(path, "%v", err)
+		}
+	}
+}
+
+// stringifyNumbers rewrites whole numbers in raw as strings wherever t has a
+// string, so YAML and TOML values such as term: 202601, campus: 0 and
+// crns: [12345] don't need quotes. A number where a Watch goes is its CRN.
+func stringifyNumbers(raw any, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch value := raw.(type) {
+	case float64:
+		if (t.Kind() == reflect.String || t == reflect.TypeOf(Watch{})) && value == math.Trunc(value) && math.Abs(value) < 1e15 {
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Map:
+			for key, item := range value {
+				value[key] = stringifyNumbers(item, t.Elem())
+			}
+		case reflect.Struct:
+			fields := jsonFields(t)
+			for key, item := range value {
+				if field, ok := fields[key]; ok {
+					value[key] = stringifyNumbers(item, field.Type)
+				}
+			}
+		}
+	case []any:
+		if t.Kind() == reflect.Slice {
+			for i, item := range value {
+				value[i] = stringifyNumbers(item, t.Elem())
+			}
+		}
+	}
+	return raw
+}
+
+// jsonFields maps the JSON keys of a struct to its fields
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if key != "" && key != "-" && f.IsExported() {
+			fields[key] = f
+		}
+	}
+	return fields
+}
+
+// suggestField points at the intended key when an unknown one differs only in case
+func suggestField(key string, fields map[string]reflect.StructField) string {
+	for known := range fields {
+		if strings.EqualFold(known, key) {
+			return fmt.Sprintf(" (did you mean %q?)", known)
+		}
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// validate checks every value's format and the optional sections, returning all problems found
+func (c Config) validate() ConfigErrors {
+	var errs ConfigErrors
+
+	if len(c.CRNs) == 0 && len(c.Profiles) == 0 {
+		errs.add("crns", "no CRNs specified (set crns, OPENSEAT_CRNS or --crn)")
+	}
+	checkCRNs(c.CRNs.CRNs(), "crns", &errs)
+	for i, w := range c.CRNs {
+		w.validate(fmt.Sprintf("crns[%d]", i), &errs)
+	}
+
+	if _, err := ParseTerm(string(c.Term), time.Now()); err != nil {
+		errs.add("term", "%v", err)
+	}
+	if !campusPattern.MatchString(c.Campus) {
+		errs.add("campus", "%q is not a campus code; use a number such as 0 (Blacksburg)", c.Campus)
+	}
+	if c.CheckInterval < minCheckInterval {
+		errs.add("checkInterval", "must be at least %d seconds, got %d", minCheckInterval, c.CheckInterval)
+	}
+	if c.Email != "" {
+		if addr, err := mail.ParseAddress(c.Email); err != nil || addr.Address != c.Email {
+			errs.add("email", "%q is not a valid email address", c.Email)
+		}
+	}
+
+	if c.SMS != nil {
+		checkCRNs(c.SMS.CRNs, "sms.crns", &errs)
+	}
+	if c.Digest != nil {
+		c.Digest.validate("digest", &errs)
+	}
+	if c.QuietHours != nil {
+		c.QuietHours.validate("quietHours", &errs)
+	}
+	if c.Escalation != nil {
+		c.Escalation.validate("escalation", &errs)
+	}
+	if c.Quotas != nil {
+		c.Quotas.validate("quotas", &errs)
+	}
+	for _, channel := range sortedKeys(c.Alerts.ChannelCooldowns) {
+		checkChannel(channel, joinPath("alerts.channelCooldowns", channel), &errs)
+	}
+	for i, channel := range c.Alerts.UrgentChannels {
+		checkChannel(channel, fmt.Sprintf("alerts.urgentChannels[%d]", i), &errs)
+	}
+
+	names := map[string]int{}
+	// two listeners polling one bot would each get only some of its commands
+	bots := map[string]string{}
+	if c.Telegram != nil {
+		c.Telegram.checkBot("telegram", bots, &errs)
+	}
+	for i, p := range c.Profiles {
+		path := fmt.Sprintf("profiles[%d]", i)
+		p.validate(path, &errs)
+		switch first, dup := names[p.Name]; {
+		case p.Name == defaultProfile:
+			errs.add(path+".name", "%q is reserved for the top-level crns and channels", p.Name)
+		case dup:
+			errs.add(path+".name", "%q is already used by profiles[%d]", p.Name, first)
+		default:
+			names[p.Name] = i
+		}
+		if p.Telegram != nil {
+			p.Telegram.checkBot(path+".telegram", bots, &errs)
+		}
+	}
+	return errs
+}
+
+// checkChannel reports a channel name that isn't one of channelNames
+func checkChannel(channel, path string, errs *ConfigErrors) {
+	if !slices.Contains(channelNames, channel) {
+		errs.add(path, "unknown channel %q; channels are %s", channel, strings.Join(channelNames, ", "))
+	}
+}
+
+// checkCRNs reports malformed and repeated CRNs in a list
+func checkCRNs(crns []string, path string, errs *ConfigErrors) {
+	seen := map[string]int{}
+	for i, crn := range crns {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if !crnPattern.MatchString(crn) {
+			errs.add(itemPath, "%q is not a CRN; CRNs are 5 digits, e.g. 12345", crn)
+			continue
+		}
+		if first, dup := seen[crn]; dup {
+			errs.add(itemPath, "%s is already listed at %s[%d]", crn, path, first)
+			continue
+		}
+		seen[crn] = i
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ===================
+// Config validation tests
+// ===================
+
+func TestLoadConfig_ReportsEveryProblem(t *testing.T) {
+	path := createTempConfig(t, `{
+		"crns": ["12345", "1234", "12345"],
+		"term": "2026-01",
+		"checkInterval": 1,
+		"email": "not-an-email",
+		"ntfy": {"topik": "seats"},
+		"escalation": {"steps": [{"chanel": "sms"}]},
+		"Continuous": true
+	}`)
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	var errs ConfigErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ConfigErrors, got %v", err)
+	}
+
+	want := []string{
+		`crns[1]: "1234" is not a CRN`,
+		`crns[2]: 12345 is already listed at crns[0]`,
+		`term: "2026-01" is not a term code`,
+		`checkInterval: must be at least 10 seconds, got 1`,
+		`email: "not-an-email" is not a valid email address`,
+		`ntfy.topik: unknown field`,
+		`escalation.steps[0].chanel: unknown field`,
+		`Continuous: unknown field (did you mean "continuous"?)`,
+	}
+	msg := err.Error()
+	for _, w := range want {
+		if !strings.Contains(msg, w) {
+			t.Errorf("error missing %q:\n%s", w, msg)
+		}
+	}
+}
+
+func TestLoadConfig_TypeErrorHasPath(t *testing.T) {
+	path := createTempConfig(t, `{"crns": ["12345"], "checkInterval": "60"}`)
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil || !strings.Contains(err.Error(), "checkInterval: expected a whole number, got string") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfig_ReportsEveryTypeError(t *testing.T) {
+	path := createTempConfig(t, `{
+		"crns": ["12345"],
+		"checkInterval": "30",
+		"campus": true,
+		"ntfy": {"topic": "seats", "priority": "high", "tags": "school"},
+		"quotas": {"channels": {"sms": {"daily": "ten"}}}
+	}`)
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	var errs ConfigErrors
+	if !errors.As(err, &errs) || len(errs) != 5 {
+		t.Fatalf("expected 5 problems, got %v", err)
+	}
+	for _, w := range []string{
+		"campus: expected a string, got bool",
+		"checkInterval: expected a whole number, got string",
+		"ntfy.priority: expected a whole number, got string",
+		"ntfy.tags: expected a list, got string",
+		"quotas.channels.sms.daily: expected a whole number, got string",
+	} {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error missing %q:\n%v", w, err)
+		}
+	}
+}
+
+func TestLoadConfig_FractionWhereWholeNumberExpected(t *testing.T) {
+	path := createTempConfig(t, `{"crns": ["12345"], "checkInterval": 30.5}`)
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil || !strings.Contains(err.Error(), "checkInterval: expected a whole number, got 30.5") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfig_TypeErrorNotReportedTwice(t *testing.T) {
+	path := createTempConfig(t, `{"crns": "12345", "term": true}`)
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	var errs ConfigErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 problems, got %v", err)
+	}
+	for _, w := range []string{
+		"crns: expected a list, got string",
+		"term: expected a string, got bool",
+	} {
+		if !strings.Contains(err.Error(), w) {
+			t.Errorf("error missing %q:\n%v", w, err)
+		}
+	}
+}
+
+func TestLoadConfig_SyntaxErrorPosition(t *testing.T) {
+	path := createTempConfig(t, "{\n  \"crns\": [\"12345\",]\n}")
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected line number in error, got %v", err)
+	}
+}
+
+func TestConfigValidate_TermMonths(t *testing.T) {
+	tests := []struct {
+		term string
+		ok   bool
+	}{
+		{"202601", true},
+		{"202506", true},
+		{"202507", true},
+		{"202509", true},
+		{"202603", false},
+		{"26-01", false},
+		{"Spring 2026", true},
+		{"next", true},
+		{"Summer 2026", false},
+	}
+	for _, tt := range tests {
+		cfg := Config{CRNs: newWatchList("12345"), Term: Term(tt.term), Campus: "0", CheckInterval: 30}
+		errs := cfg.validate()
+		if got := len(errs) == 0; got != tt.ok {
+			t.Errorf("term %q valid = %v, want %v (%v)", tt.term, got, tt.ok, errs)
+		}
+	}
+}
+
+func TestConfigValidate_ChannelReferences(t *testing.T) {
+	cfg := Config{
+		CRNs: newWatchList("12345"), Term: "202601", Campus: "0", CheckInterval: 30,
+		Alerts: AlertPolicy{ChannelCooldowns: map[string]int{"emial": 60}, UrgentChannels: []string{"pushover"}},
+		QuietHours: &QuietHoursConfig{Channels: map[string]QuietWindow{
+			"sms":  {Start: "22:00", End: "7am", Action: "downgrade", Fallback: "nfty"},
+			"text": {Start: "22:00", End: "07:00"},
+		}},
+		Escalation: &EscalationConfig{Steps: []EscalationStep{{Channel: "email", After: 60}, {Channel: "ntfy", After: 30}, {Channel: "pager", After: 90}}},
+		Quotas:     &QuotaConfig{Channels: map[string]ChannelQuota{"emails": {Daily: -1}, "sms": {Fallback: "phone"}}},
+	}
+	errs := cfg.validate()
+	want := []string{
+		`quietHours.channels.sms.end: must be HH:MM, got "7am"`,
+		`quietHours.channels.sms.fallback: unknown channel "nfty"`,
+		`quietHours.channels.text: unknown channel "text"`,
+		`escalation.steps[1].after: must not come before the previous step`,
+		`escalation.steps[2].channel: unknown channel "pager"`,
+		`quotas.channels.emails: unknown channel "emails"`,
+		`quotas.channels.emails.daily: must not be negative`,
+		`quotas.channels.sms.fallback: unknown channel "phone"`,
+		`alerts.channelCooldowns.emial: unknown channel "emial"`,
+		`alerts.urgentChannels[0]: unknown channel "pushover"`,
+	}
+	if len(errs) != len(want) {
+		t.Errorf("got %d problems, want %d:\n%v", len(errs), len(want), errs)
+	}
+	for _, w := range want {
+		if !strings.Contains(errs.Error(), w) {
+			t.Errorf("error missing %q:\n%v", w, errs)
+		}
+	}
+}
+
+func TestConfigValidate_ChannelCRNLists(t *testing.T) {
+	cfg := Config{
+		CRNs: newWatchList("12345"), Term: "202601", Campus: "0", CheckInterval: 30,
+		SMS:        &SMSConfig{CRNs: []string{"abc"}},
+		QuietHours: &QuietHoursConfig{Critical: []string{"999999"}},
+	}
+	msg := cfg.validate().Error()
+	for _, w := range []string{"sms.crns[0]", "quietHours.critical[0]"} {
+		if !strings.Contains(msg, w) {
+			t.Errorf("error missing %q:\n%s", w, msg)
+		}
+	}
+}
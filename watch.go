@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Priority changes how often a CRN is checked and whether its openings escalate
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"    // checked half as often, never escalates
+	PriorityNormal Priority = "normal" // checked every checkInterval
+	PriorityHigh   Priority = "high"   // checked twice as often, always escalates
+)
+
+// channelNames lists every notification channel by its config key
+var channelNames = []string{"email", "ntfy", "gotify", "telegram", "sms", "desktop", "exec"}
+
+// Watch is one monitored CRN. In config it is either a plain CRN string or an
+// object with settings for that CRN.
+type Watch struct {
+	CRN      string   `json:"crn"`                // Course Reference Number (required)
+	Label    string   `json:"label,omitempty"`    // Name shown instead of the timetable title (optional)
+	Priority Priority `json:"priority,omitempty"` // "low", "normal" or "high" (defaults to normal)
+	Interval int      `json:"interval,omitempty"` // Seconds between checks of this CRN (defaults to checkInterval, adjusted for priority)
+	MinSeats int      `json:"minSeats,omitempty"` // Open seats needed before alerting (default 1)
+	Channels []string `json:"channels,omitempty"` // Channels to notify about this CRN (defaults to all)
+	Expires  string   `json:"expires,omitempty"`  // Stop watching after this date, YYYY-MM-DD or RFC 3339 (optional)
+}
+
+// WatchList is the monitored CRNs with their settings
+type WatchList []Watch
+
+// newWatchList creates watches with default settings for the given CRNs
+func newWatchList(crns ...string) WatchList {
+	list := make(WatchList, 0, len(crns))
+	for _, crn := range crns {
+		list = append(list, Watch{CRN: crn})
+	}
+	return list
+}
+
+// CRNs returns the CRN of every watch, in order
+func (l WatchList) CRNs() []string {
+	crns := make([]string, 0, len(l))
+	for _, w := range l {
+		crns = append(crns, w.CRN)
+	}
+	return crns
+}
+
+// Get returns the settings for crn, or defaults if it isn't in the list
+// (e.g. a CRN added with /add)
+func (l WatchList) Get(crn string) Watch {
+	if i := slices.IndexFunc(l, func(w Watch) bool { return w.CRN == crn }); i >= 0 {
+		return l[i]
+	}
+	return Watch{CRN: crn}
+}
+
+// channels maps each CRN limited to certain channels to those channels
+func (l WatchList) channels() map[string][]string {
+	routes := map[string][]string{}
+	for _, w := range l {
+		if len(w.Channels) > 0 {
+			routes[w.CRN] = w.Channels
+		}
+	}
+	return routes
+}
+
+// priorities maps each CRN with a non-default priority to its priority
+func (l WatchList) priorities() map[string]Priority {
+	priorities := map[string]Priority{}
+	for _, w := range l {
+		if w.Priority != "" && w.Priority != PriorityNormal {
+			priorities[w.CRN] = w.Priority
+		}
+	}
+	return priorities
+}
+
+// expandWatches rewrites plain CRN strings in a decoded config's crns lists
+// (top-level and per profile) as {"crn": ...} objects, so both forms decode
+// into Watch with the same checks
+func expandWatches(raw any) {
+	cfg, ok := raw.(map[string]any)
+	if !ok {
+		return
+	}
+	if profiles, ok := cfg["profiles"].([]any); ok {
+		for _, p := range profiles {
+			expandWatches(p)
+		}
+	}
+	items, ok := cfg["crns"].([]any)
+	if !ok {
+		return
+	}
+	for i, item := range items {
+		if crn, ok := item.(string); ok {
+			items[i] = map[string]any{"crn": crn}
+		}
+	}
+}
+
+// displayName returns the label if one is set, otherwise the timetable title
+func (w Watch) displayName(title string) string {
+	if w.Label != "" {
+		return w.Label
+	}
+	return title
+}
+
+// interval returns the time between checks of this CRN. An explicit interval
+// wins; otherwise high priority halves checkInterval (but not below the
+// minimum) and low priority doubles it.
+func (w Watch) interval(checkInterval int) time.Duration {
+	secs := checkInterval
+	switch {
+	case w.Interval > 0:
+		secs = w.Interval
+	case w.Priority == PriorityHigh:
+		secs = max(checkInterval/2, minCheckInterval)
+	case w.Priority == PriorityLow:
+		secs = checkInterval * 2
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// minSeats returns how many open seats count as an opening
+func (w Watch) minSeats() int {
+	return max(w.MinSeats, 1)
+}
+
+// expiry returns when the watch ends, or the zero time if it doesn't. A date
+// without a time expires at the end of that day, local time.
+func (w Watch) expiry() (time.Time, error) {
+	if w.Expires == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.ParseInLocation(time.DateOnly, w.Expires, time.Local); err == nil {
+		return day.AddDate(0, 0, 1), nil
+	}
+	return time.Parse(time.RFC3339, w.Expires)
+}
+
+// expired reports whether the watch has passed its expiry
+func (w Watch) expired(now time.Time) bool {
+	end, err := w.expiry()
+	return err == nil && !end.IsZero() && !now.Before(end)
+}
+
+// validate reports problems with the watch's settings under path, e.g. "crns[2]"
+func (w Watch) validate(path string, errs *ConfigErrors) {
+	switch w.Priority {
+	case "", PriorityLow, PriorityNormal, PriorityHigh:
+	default:
+		errs.add(path+".priority", "must be \"low\", \"normal\" or \"high\", got %q", w.Priority)
+	}
+	if w.Interval != 0 && w.Interval < minCheckInterval {
+		errs.add(path+".interval", "must be at least %d seconds, got %d", minCheckInterval, w.Interval)
+	}
+	if w.MinSeats < 0 {
+		errs.add(path+".minSeats", "must not be negative, got %d", w.MinSeats)
+	}
+	for i, channel := range w.Channels {
+		checkChannel(channel, fmt.Sprintf("%s.channels[%d]", path, i), errs)
+	}
+	if _, err := w.expiry(); err != nil {
+		errs.add(path+".expires", "%q is not a date; use YYYY-MM-DD or RFC 3339, e.g. 2026-01-20", w.Expires)
+	}
+}
+
+// nextCheck returns when the next unfound course is due, given when each was
+// last checked. Courses never checked are due immediately.
+func nextCheck(courses []CourseStatus, lastChecked map[string]time.Time, watches WatchList, checkInterval int, now time.Time) time.Time {
+	next := now.Add(time.Duration(checkInterval) * time.Second)
+	for _, c := range courses {
+		if c.Found {
+			continue
+		}
+		if due := lastChecked[c.CRN].Add(watches.Get(c.CRN).interval(checkInterval)); due.Before(next) {
+			next = due
+		}
+	}
+	return next
+}
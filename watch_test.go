@@ -0,0 +1,194 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ===================
+// Per-CRN watch tests
+// ===================
+
+func TestLoadConfig_MixedWatchForms(t *testing.T) {
+	path := createTempConfig(t, `{
+		"crns": [
+			"12345",
+			{"crn": "67890", "label": "Lab", "priority": "high", "minSeats": 2, "channels": ["sms"], "expires": "2026-01-20"}
+		]
+	}`)
+
+	cfg, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.CRNs.CRNs(); len(got) != 2 || got[0] != "12345" || got[1] != "67890" {
+		t.Fatalf("CRNs = %v", got)
+	}
+	if w := cfg.CRNs[0]; w.Label != "" || w.Priority != "" {
+		t.Errorf("plain CRN should have default settings, got %+v", w)
+	}
+	lab := cfg.CRNs.Get("67890")
+	if lab.Label != "Lab" || lab.Priority != PriorityHigh || lab.minSeats() != 2 || lab.Channels[0] != "sms" {
+		t.Errorf("unexpected watch settings: %+v", lab)
+	}
+}
+
+func TestLoadConfig_WatchProblemsHavePaths(t *testing.T) {
+	path := createTempConfig(t, `{
+		"crns": [
+			{"crn": "12345", "lable": "x"},
+			{"crn": "67890", "interval": "60"},
+			{"crn": "11111", "priority": "urgent", "interval": 5, "channels": ["pager"], "expires": "next week"}
+		]
+	}`)
+
+	_, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		`crns[0].lable: unknown field`,
+		`crns[1].interval: expected a whole number, got string`,
+		`crns[2].priority: must be "low", "normal" or "high"`,
+		`crns[2].interval: must be at least 10 seconds`,
+		`crns[2].channels[0]: unknown channel "pager"`,
+		`crns[2].expires: "next week" is not a date`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+
+	path = createTempConfig(t, `{"crns": [true, 42]}`)
+	_, err = loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil)})
+	if err == nil || !strings.Contains(err.Error(), "crns[0]: expected a CRN or an object, got bool") || !strings.Contains(err.Error(), `crns[1]: "42" is not a CRN`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadConfig_FlagCRNsKeepFileSettings(t *testing.T) {
+	path := createTempConfig(t, `{"crns": [{"crn": "12345", "label": "Lecture"}]}`)
+
+	cfg, err := loadConfigFrom(ConfigSources{Path: path, LookupEnv: fakeEnv(nil), Flags: ConfigOverrides{CRNs: []string{"12345", "67890"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CRNs.Get("12345").Label != "Lecture" || len(cfg.CRNs) != 2 {
+		t.Errorf("unexpected watches: %+v", cfg.CRNs)
+	}
+}
+
+func TestWatch_Interval(t *testing.T) {
+	tests := []struct {
+		watch Watch
+		want  time.Duration
+	}{
+		{Watch{}, 30 * time.Second},
+		{Watch{Priority: PriorityHigh}, 15 * time.Second},
+		{Watch{Priority: PriorityLow}, time.Minute},
+		{Watch{Priority: PriorityLow, Interval: 20}, 20 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.watch.interval(30); got != tt.want {
+			t.Errorf("%+v: interval = %v, want %v", tt.watch, got, tt.want)
+		}
+	}
+	if got := (Watch{Priority: PriorityHigh}).interval(12); got != minCheckInterval*time.Second {
+		t.Errorf("high priority went below the minimum interval: %v", got)
+	}
+}
+
+func TestWatch_Expired(t *testing.T) {
+	w := Watch{Expires: "2026-01-20"}
+	if w.expired(time.Date(2026, 1, 20, 23, 0, 0, 0, time.Local)) {
+		t.Error("expected the watch to last through its expiry date")
+	}
+	if !w.expired(time.Date(2026, 1, 21, 0, 0, 0, 0, time.Local)) {
+		t.Error("expected the watch to expire the day after")
+	}
+	if (Watch{}).expired(time.Now()) {
+		t.Error("a watch without an expiry never expires")
+	}
+}
+
+func TestNextCheck(t *testing.T) {
+	now := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	watches := WatchList{{CRN: "12345"}, {CRN: "67890", Interval: 10}}
+	courses := []CourseStatus{{CRN: "12345"}, {CRN: "67890"}, {CRN: "11111", Found: true}}
+	last := map[string]time.Time{"12345": now, "67890": now.Add(-5 * time.Second), "11111": now}
+
+	if got := nextCheck(courses, last, watches, 30, now); !got.Equal(now.Add(5 * time.Second)) {
+		t.Errorf("nextCheck = %v, want 5s from now", got.Sub(now))
+	}
+	delete(last, "12345")
+	if got := nextCheck(courses, last, watches, 30, now); got.After(now) {
+		t.Errorf("a course never checked should be due now, got %v", got.Sub(now))
+	}
+}
+
+func TestDispatcher_WatchChannels(t *testing.T) {
+	email := &countingNotifier{name: "email"}
+	sms := &countingNotifier{name: "sms"}
+	d := NewDispatcher([]Notifier{email, sms}, AlertPolicy{})
+	d.configure(func(s *dispatchSettings) {
+		s.channels = WatchList{{CRN: "12345", Channels: []string{"sms"}}}.channels()
+	})
+
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "12345", Name: "CS-3114"})
+	d.Dispatch(Event{Kind: EventSeatOpen, CRN: "67890", Name: "MATH-1226"})
+	d.Flush()
+
+	if len(email.events) != 1 || email.events[0].CRN != "67890" {
+		t.Errorf("email should only hear about 67890, got %+v", email.events)
+	}
+	if len(sms.events) != 1 || len(sms.events[0].CRNs()) != 2 {
+		t.Errorf("sms should hear about both, got %+v", sms.events)
+	}
+}
+
+func TestEscalator_Priority(t *testing.T) {
+	e := NewEscalator(EscalationConfig{CRNs: []string{"12345"}, Steps: []EscalationStep{{Channel: "sms"}}})
+	e.priority = WatchList{{CRN: "12345", Priority: PriorityLow}, {CRN: "67890", Priority: PriorityHigh}}.priorities()
+
+	if e.handles(Event{Kind: EventSeatOpen, CRN: "12345"}) {
+		t.Error("low priority CRNs should not escalate")
+	}
+	if !e.handles(Event{Kind: EventSeatOpen, CRN: "67890"}) {
+		t.Error("high priority CRNs should always escalate")
+	}
+}
+
+func TestPollCourse_MinSeats(t *testing.T) {
+	seats := "1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<table class="dataentrytable">
+			<tr><th>CRN</th><th>Course</th><th>Title</th><th>Seats</th></tr>
+			<tr><td>12345</td><td>CS-3114</td><td>Data Structures</td><td>` + seats + `</td></tr>
+		</table>`))
+	}))
+	defer server.Close()
+
+	cfg := Config{BaseURL: server.URL, CRNs: WatchList{{CRN: "12345", MinSeats: 2}}}
+	push := &countingNotifier{name: "ntfy"}
+	d := NewDispatcher([]Notifier{push}, AlertPolicy{})
+	monitor := NewMonitor(cfg, []CourseStatus{{CRN: "12345", Name: "Data Structures"}})
+
+	pollCourse(cfg, monitor, d, monitor.Status()[0], "09:00:00")
+	d.Flush()
+	if push.sent != 0 {
+		t.Fatal("one seat is below the threshold of two, expected no alert")
+	}
+	if got := monitor.Status()[0].Stats.Seats; got != 1 {
+		t.Errorf("recorded seats = %d, want 1", got)
+	}
+
+	seats = "2"
+	pollCourse(cfg, monitor, d, monitor.Status()[0], "09:00:30")
+	d.Flush()
+	if push.sent != 1 {
+		t.Errorf("expected an alert once two seats opened, got %d", push.sent)
+	}
+}